@@ -10,6 +10,7 @@ import (
 
 	"github.com/marty-cz/czech-tax-calculator/internal/export"
 	"github.com/marty-cz/czech-tax-calculator/internal/ingest"
+	"github.com/marty-cz/czech-tax-calculator/internal/rules"
 	"github.com/marty-cz/czech-tax-calculator/internal/tax"
 	"github.com/marty-cz/czech-tax-calculator/internal/util"
 )
@@ -27,9 +28,27 @@ func init() {
 func main() {
 	stockInputPath := flag.String("stock-input", "", "File path to input file with Stocks transaction records")
 	cryptoInputPath := flag.String("crypto-input", "", "File path to input file with Crypto-currencies transaction records")
+	derivativeInputPath := flag.String("derivative-input", "", "File path to input file with Derivatives (options, futures, CFDs) transaction records")
 	targetYear := flag.String("year", fmt.Sprint(time.Now().Year()-1), "Target year for taxes")
+	purchasePriceMethodName := flag.String("purchase-price-method", tax.FIFO.Name(), "Method of purchase price calculation ('fifo', 'average' or what-if only 'lifo')")
+	additionalTaxBase := flag.Float64("additional-tax-base", 0, "Sum of partial tax bases (in CZK) of the target year not covered by input files (e.g. employment income) to calculate tax liability")
+	allowOversell := flag.Bool("allow-oversell", false, "Report sells not covered by buys instead of failing")
+	allowShort := flag.Bool("allow-short", false, "Open short positions by sells not covered by held items (covered by later buys)")
+	perBrokerPools := flag.Bool("per-broker-pools", false, "Match sells with buys of the same broker only (items are moved between brokers by transfers)")
 	flag.Parse()
 
+	targetTaxYear, err := util.GetYearFromString(*targetYear)
+	if err != nil {
+		log.Fatalf("invalid target year: %v", err)
+	}
+	purchasePriceMethod, err := tax.GetCostBasisStrategyByName(*purchasePriceMethodName)
+	if err != nil {
+		log.Fatalf("invalid purchase price method: %v", err)
+	}
+	if !purchasePriceMethod.IsFilingMethod() {
+		log.Warnf("purchase price method '%s' is for comparison only and cannot be used for tax filing", purchasePriceMethod.Name())
+	}
+
 	// pre-check of Year change rate to CZK availability
 	for year := 2011; year <= time.Now().Year(); year++ {
 		if val, err := util.GetCzkExchangeRateInYear(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC), *util.USD); err != nil || val <= 0.0 {
@@ -37,13 +56,24 @@ func main() {
 		}
 	}
 
-	// process input files
-	stockTaxReports := createTaxReport(*stockInputPath, *targetYear, ingest.StockItemType, ingest.ProcessStocks)
-	cryptoTaxReports := createTaxReport(*cryptoInputPath, *targetYear, ingest.CryptoItemType, ingest.ProcessCryptos)
+	// process input files (premiums of assigned options adjust prices of the underlying stocks)
+	derivativeTaxReports, underlyingAdjustments := createDerivativeTaxReport(*derivativeInputPath, *targetYear)
+	processStocks := func(filePath string) (*ingest.TransactionLog, error) {
+		transactions, err := ingest.ProcessStocks(filePath)
+		if err == nil {
+			tax.ApplyUnderlyingAdjustments(transactions, underlyingAdjustments)
+		}
+		return transactions, err
+	}
+	stockTaxReports := createTaxReport(*stockInputPath, *targetYear, purchasePriceMethod, *allowOversell, *allowShort, *perBrokerPools, ingest.StockItemType, processStocks, tax.StockRules)
+	cryptoTaxReports := createTaxReport(*cryptoInputPath, *targetYear, purchasePriceMethod, *allowOversell, *allowShort, *perBrokerPools, ingest.CryptoItemType, ingest.ProcessCryptos, tax.CryptoRules)
 
 	// write to output file
-	statements := createStatementMap(stockTaxReports, cryptoTaxReports)
+	statements := createStatementMap(stockTaxReports, cryptoTaxReports, derivativeTaxReports, *additionalTaxBase, targetTaxYear)
 	for _, statement := range statements {
+		log.Infof("tax liability for '%d': tax due %.2f CZK with DAY exchange rate, %.2f CZK with YEAR exchange rate (of it caused by input files %.2f CZK and %.2f CZK)",
+			statement.Year, statement.TaxLiability.TaxDue.ValueWithDayExchangeRate, statement.TaxLiability.TaxDue.ValueWithYearExchangeRate,
+			statement.TaxLiability.ReportsTaxDue.ValueWithDayExchangeRate, statement.TaxLiability.ReportsTaxDue.ValueWithYearExchangeRate)
 		if err := export.ExportToExcel(statement, fmt.Sprintf("./tax-statement-%d.xlsx", statement.Year)); err != nil {
 			log.Errorf("cannot create excel statement for year '%d'", statement.Year)
 		} else {
@@ -53,7 +83,7 @@ func main() {
 
 }
 
-func createTaxReport(sourceFilePath string, targetYear string, itemTypeString string, ingestFn func(string) (*ingest.TransactionLog, error)) (taxReports tax.Reports) {
+func createTaxReport(sourceFilePath string, targetYear string, purchasePriceMethod tax.CostBasisStrategy, allowOversell bool, allowShort bool, perBrokerPools bool, itemTypeString string, ingestFn func(string) (*ingest.TransactionLog, error), rules tax.RulesProvider) (taxReports tax.Reports) {
 	if sourceFilePath != "" {
 		transactions, err := ingestFn(sourceFilePath)
 		if err != nil {
@@ -61,7 +91,7 @@ func createTaxReport(sourceFilePath string, targetYear string, itemTypeString st
 		} else {
 			log.Infof("%ss: all ingested", itemTypeString)
 
-			taxReports, err = tax.Calculate(transactions, targetYear, true)
+			taxReports, err = tax.Calculate(transactions, targetYear, rules, purchasePriceMethod, allowOversell, allowShort, perBrokerPools)
 			if err != nil {
 				log.Errorf("%ss: cannot create tax report due to: %s", itemTypeString, err)
 			} else {
@@ -72,7 +102,27 @@ func createTaxReport(sourceFilePath string, targetYear string, itemTypeString st
 	return
 }
 
-func createStatementMap(stockTaxReports, cryptoTaxReports tax.Reports) (statements map[int]*export.Statement) {
+func createDerivativeTaxReport(sourceFilePath string, targetYear string) (taxReports tax.Reports, adjustments tax.UnderlyingAdjustments) {
+	if sourceFilePath != "" {
+		transactions, err := ingest.ProcessDerivatives(sourceFilePath)
+		if err != nil {
+			log.Errorf("%ss: cannot ingest input file '%s' due to: %s", ingest.DerivativeItemType, sourceFilePath, err)
+		} else {
+			log.Infof("%ss: all ingested", ingest.DerivativeItemType)
+
+			taxReports, adjustments, err = tax.CalculateDerivatives(transactions, targetYear)
+			if err != nil {
+				log.Errorf("%ss: cannot create tax report due to: %s", ingest.DerivativeItemType, err)
+			} else {
+				log.Infof("%ss tax: Calculated (reports count: %d, assigned options: %d)", ingest.DerivativeItemType, len(taxReports), len(adjustments))
+			}
+		}
+	}
+	return
+}
+
+// The additional tax base is supplied for the target tax year only, tax liability of other years is calculated without it
+func createStatementMap(stockTaxReports, cryptoTaxReports, derivativeTaxReports tax.Reports, additionalTaxBase float64, targetTaxYear int) (statements map[int]*export.Statement) {
 	statements = make(map[int]*export.Statement)
 	for _, stockReport := range stockTaxReports {
 		year := stockReport.Year.Year()
@@ -100,5 +150,24 @@ func createStatementMap(stockTaxReports, cryptoTaxReports tax.Reports) (statemen
 			statements[year].Year = year
 		}
 	}
+	for _, derivativeReport := range derivativeTaxReports {
+		year := derivativeReport.Year.Year()
+		if statements[year] == nil {
+			statements[year] = &export.Statement{Year: year}
+		}
+		statements[year].DerivativeReport = derivativeReport
+	}
+	// exemption cap and notification obligation of time tested revenue are shared by stocks and cryptos
+	for year, statement := range statements {
+		ruleSet := rules.ForYear(year)
+		tax.ApplyTimeTestedExemptionCap(ruleSet.TimeTestedExemptionCap, statement.StockReport, statement.CryptoReport)
+		statement.NotificationObligation = tax.EvaluateNotificationObligation(ruleSet.ExemptRevenueReportingLimit, statement.StockReport, statement.CryptoReport)
+		yearAdditionalTaxBase := 0.0
+		if year == targetTaxYear {
+			yearAdditionalTaxBase = additionalTaxBase
+		}
+		statement.TaxLiability = tax.CalculateTaxLiability(ruleSet, yearAdditionalTaxBase, statement.StockReport, statement.CryptoReport, statement.DerivativeReport)
+		statement.TaxLiability.AdditionalTaxBaseSupplied = year == targetTaxYear
+	}
 	return
 }
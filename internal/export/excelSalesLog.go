@@ -23,6 +23,7 @@ func salesLogToExcel(w *util.ExcelWriter, sales tax.SellOperations, itemTypeStri
 	w.WriteCell(sheet, row, col+7, "Buy Price (Year ExR, FIFO)")
 	w.WriteCell(sheet, row, col+8, "Fee (Day ExR, FIFO)")
 	w.WriteCell(sheet, row, col+9, "Fee (Year ExR, FIFO)")
+	w.WriteCell(sheet, row, col+10, "Time Test Rule")
 
 	// write log
 	for _, sellOp := range sales {
@@ -39,8 +40,52 @@ func salesLogToExcel(w *util.ExcelWriter, sales tax.SellOperations, itemTypeStri
 			w.WriteAccountingCell(sheet, row, col+7, soldItem.FifoBuy.Value.ValueWithYearExchangeRate, soldItem.FifoBuy.Value.Currency)
 			w.WriteAccountingCell(sheet, row, col+8, soldItem.FifoBuy.Fee.ValueWithDayExchangeRate+soldItem.Revenue.Fee.ValueWithDayExchangeRate, soldItem.FifoBuy.Value.Currency)
 			w.WriteAccountingCell(sheet, row, col+9, soldItem.FifoBuy.Fee.ValueWithYearExchangeRate+soldItem.Revenue.Fee.ValueWithYearExchangeRate, soldItem.FifoBuy.Value.Currency)
+			w.WriteCell(sheet, row, col+10, soldItem.TimeTestRule)
 		}
 	}
 
 	return nil
 }
+
+func closedPositionsLogToExcel(w *util.ExcelWriter, positions tax.ClosedPositions, itemTypeString string) error {
+
+	sheet := "Positions - " + itemTypeString
+	// Create a new sheet.
+	w.File.NewSheet(sheet)
+
+	// write header
+	row, col := 0, 0
+	w.WriteCell(sheet, row, col, itemTypeString)
+	w.WriteCell(sheet, row, col+1, "Side")
+	w.WriteCell(sheet, row, col+2, "Quantity")
+	w.WriteCell(sheet, row, col+3, "Open Date")
+	w.WriteCell(sheet, row, col+4, "Close Date")
+	w.WriteCell(sheet, row, col+5, "Revenue (Day ExR)")
+	w.WriteCell(sheet, row, col+6, "Revenue (Year ExR)")
+	w.WriteCell(sheet, row, col+7, "Expense (Day ExR)")
+	w.WriteCell(sheet, row, col+8, "Expense (Year ExR)")
+	w.WriteCell(sheet, row, col+9, "Fee (Day ExR)")
+	w.WriteCell(sheet, row, col+10, "Fee (Year ExR)")
+
+	// write log
+	for _, position := range positions {
+		row++
+		side := "LONG"
+		if position.Short {
+			side = "SHORT"
+		}
+		w.WriteCell(sheet, row, col, position.OpenItem.Name)
+		w.WriteCell(sheet, row, col+1, side)
+		w.WriteCell(sheet, row, col+2, position.Quantity)
+		w.WriteDateCell(sheet, row, col+3, position.OpenItem.Date)
+		w.WriteDateCell(sheet, row, col+4, position.CloseItem.Date)
+		w.WriteAccountingCell(sheet, row, col+5, position.Revenue.ValueWithDayExchangeRate, position.Revenue.Currency)
+		w.WriteAccountingCell(sheet, row, col+6, position.Revenue.ValueWithYearExchangeRate, position.Revenue.Currency)
+		w.WriteAccountingCell(sheet, row, col+7, position.Expense.ValueWithDayExchangeRate, position.Expense.Currency)
+		w.WriteAccountingCell(sheet, row, col+8, position.Expense.ValueWithYearExchangeRate, position.Expense.Currency)
+		w.WriteAccountingCell(sheet, row, col+9, position.Fee.ValueWithDayExchangeRate, position.Fee.Currency)
+		w.WriteAccountingCell(sheet, row, col+10, position.Fee.ValueWithYearExchangeRate, position.Fee.Currency)
+	}
+
+	return nil
+}
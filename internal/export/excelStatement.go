@@ -3,6 +3,7 @@ package export
 import (
 	"fmt"
 
+	"github.com/marty-cz/czech-tax-calculator/internal/rules"
 	"github.com/marty-cz/czech-tax-calculator/internal/tax"
 	"github.com/marty-cz/czech-tax-calculator/internal/util"
 )
@@ -10,7 +11,13 @@ import (
 type Statement struct {
 	StockReport  *tax.Report
 	CryptoReport *tax.Report
-	Year         int
+	// report of closed derivative positions
+	DerivativeReport *tax.Report
+	Year             int
+	// notification obligation of exempted revenue of both stocks and cryptos (nil when there is no limit)
+	NotificationObligation *tax.NotificationObligation
+	// tax liability of both stocks and cryptos
+	TaxLiability *tax.TaxLiability
 }
 
 func writeOverviewStatement(w *util.ExcelWriter, report *tax.Report, itemTypeString string) error {
@@ -37,11 +44,15 @@ func writeOverviewStatement(w *util.ExcelWriter, report *tax.Report, itemTypeStr
 	coordsSFY := w.WriteAccountingCell(sheet, row, col+2, report.TotalItemFifoExpense.Fee.ValueWithYearExchangeRate, report.TotalItemFifoExpense.Fee.Currency)
 	row++
 	w.WriteCell(sheet, row, col, "Profit")
-	coordsSPD := w.WriteAccountingEqCell(sheet, row, col+1, fmt.Sprintf("%s-%s-%s", coordsSRD, coordsSED, coordsSFD), report.Currency)
-	coordsSPY := w.WriteAccountingEqCell(sheet, row, col+2, fmt.Sprintf("%s-%s-%s", coordsSRY, coordsSEY, coordsSFY), report.Currency)
+	w.WriteAccountingEqCell(sheet, row, col+1, fmt.Sprintf("%s-%s-%s", coordsSRD, coordsSED, coordsSFD), report.Currency)
+	w.WriteAccountingEqCell(sheet, row, col+2, fmt.Sprintf("%s-%s-%s", coordsSRY, coordsSEY, coordsSFY), report.Currency)
 
 	row += 2
-	w.WriteCell(sheet, row, col, "Time tested "+itemTypeString+" (3 year test)")
+	timeTestedLabel := fmt.Sprintf("Time tested %s", itemTypeString)
+	if report.Rules != nil && report.Rules.TimeTestMonths > 0 {
+		timeTestedLabel += fmt.Sprintf(" (%d months test)", report.Rules.TimeTestMonths)
+	}
+	w.WriteCell(sheet, row, col, timeTestedLabel)
 	w.WriteCell(sheet, row, col+1, "with DAY exchange rate")
 	w.WriteCell(sheet, row, col+2, "with YEAR exchange rate")
 	row++
@@ -58,8 +69,62 @@ func writeOverviewStatement(w *util.ExcelWriter, report *tax.Report, itemTypeStr
 	coordsTSFY := w.WriteAccountingCell(sheet, row, col+2, report.TimeTestedItemFifoExpense.Fee.ValueWithYearExchangeRate, report.TimeTestedItemFifoExpense.Fee.Currency)
 	row++
 	w.WriteCell(sheet, row, col, "Profit")
-	coordsTSPD := w.WriteAccountingEqCell(sheet, row, col+1, fmt.Sprintf("%s-%s-%s", coordsTSRD, coordsTSED, coordsTSFD), report.Currency)
-	coordsTSPY := w.WriteAccountingEqCell(sheet, row, col+2, fmt.Sprintf("%s-%s-%s", coordsTSRY, coordsTSEY, coordsTSFY), report.Currency)
+	w.WriteAccountingEqCell(sheet, row, col+1, fmt.Sprintf("%s-%s-%s", coordsTSRD, coordsTSED, coordsTSFD), report.Currency)
+	w.WriteAccountingEqCell(sheet, row, col+2, fmt.Sprintf("%s-%s-%s", coordsTSRY, coordsTSEY, coordsTSFY), report.Currency)
+
+	if report.TimeTestedTaxedRevenue != nil && report.Rules != nil && report.Rules.RuleSet.TimeTestedExemptionCap > 0.0 {
+		row += 2
+		w.WriteCell(sheet, row, col, fmt.Sprintf("Time tested %s above exemption cap", itemTypeString))
+		w.WriteCell(sheet, row, col+1, "with DAY exchange rate")
+		w.WriteCell(sheet, row, col+2, "with YEAR exchange rate")
+		row++
+		w.WriteCell(sheet, row, col, "Exemption Cap")
+		w.WriteAccountingCell(sheet, row, col+1, report.Rules.RuleSet.TimeTestedExemptionCap, report.Currency)
+		w.WriteAccountingCell(sheet, row, col+2, report.Rules.RuleSet.TimeTestedExemptionCap, report.Currency)
+		row++
+		w.WriteCell(sheet, row, col, "Taxed Revenue")
+		coordsTTRD := w.WriteAccountingCell(sheet, row, col+1, report.TimeTestedTaxedRevenue.ValueWithDayExchangeRate, report.TimeTestedTaxedRevenue.Currency)
+		coordsTTRY := w.WriteAccountingCell(sheet, row, col+2, report.TimeTestedTaxedRevenue.ValueWithYearExchangeRate, report.TimeTestedTaxedRevenue.Currency)
+		row++
+		w.WriteCell(sheet, row, col, "Exempted Revenue")
+		w.WriteAccountingEqCell(sheet, row, col+1, fmt.Sprintf("%s-%s", coordsTSRD, coordsTTRD), report.Currency)
+		w.WriteAccountingEqCell(sheet, row, col+2, fmt.Sprintf("%s-%s", coordsTSRY, coordsTTRY), report.Currency)
+		row++
+		w.WriteCell(sheet, row, col, "Taxed Expense")
+		coordsTTED := w.WriteAccountingCell(sheet, row, col+1, report.TimeTestedTaxedExpense.Value.ValueWithDayExchangeRate, report.TimeTestedTaxedExpense.Value.Currency)
+		coordsTTEY := w.WriteAccountingCell(sheet, row, col+2, report.TimeTestedTaxedExpense.Value.ValueWithYearExchangeRate, report.TimeTestedTaxedExpense.Value.Currency)
+		row++
+		w.WriteCell(sheet, row, col, "Taxed Fees")
+		coordsTTFD := w.WriteAccountingCell(sheet, row, col+1, report.TimeTestedTaxedExpense.Fee.ValueWithDayExchangeRate, report.TimeTestedTaxedExpense.Fee.Currency)
+		coordsTTFY := w.WriteAccountingCell(sheet, row, col+2, report.TimeTestedTaxedExpense.Fee.ValueWithYearExchangeRate, report.TimeTestedTaxedExpense.Fee.Currency)
+		row++
+		w.WriteCell(sheet, row, col, "Taxed Profit")
+		w.WriteAccountingEqCell(sheet, row, col+1, fmt.Sprintf("%s-%s-%s", coordsTTRD, coordsTTED, coordsTTFD), report.Currency)
+		w.WriteAccountingEqCell(sheet, row, col+2, fmt.Sprintf("%s-%s-%s", coordsTTRY, coordsTTEY, coordsTTFY), report.Currency)
+	}
+
+	if exemption := report.RevenueExemption; exemption != nil {
+		row += 2
+		w.WriteCell(sheet, row, col, "Yearly revenue exemption")
+		w.WriteCell(sheet, row, col+1, "with DAY exchange rate")
+		w.WriteCell(sheet, row, col+2, "with YEAR exchange rate")
+		row++
+		w.WriteCell(sheet, row, col, "Limit")
+		w.WriteAccountingCell(sheet, row, col+1, exemption.Limit, report.Currency)
+		w.WriteAccountingCell(sheet, row, col+2, exemption.Limit, report.Currency)
+		row++
+		w.WriteCell(sheet, row, col, "Counted Revenue (not time tested)")
+		w.WriteAccountingCell(sheet, row, col+1, exemption.Revenue.ValueWithDayExchangeRate, exemption.Revenue.Currency)
+		w.WriteAccountingCell(sheet, row, col+2, exemption.Revenue.ValueWithYearExchangeRate, exemption.Revenue.Currency)
+		row++
+		w.WriteCell(sheet, row, col, "Remaining Headroom")
+		w.WriteAccountingCell(sheet, row, col+1, exemption.Headroom.ValueWithDayExchangeRate, exemption.Headroom.Currency)
+		w.WriteAccountingCell(sheet, row, col+2, exemption.Headroom.ValueWithYearExchangeRate, exemption.Headroom.Currency)
+		row++
+		w.WriteCell(sheet, row, col, "Verdict")
+		w.WriteCell(sheet, row, col+1, tax.GetExemptionVerdict(exemption.ExemptWithDayExchangeRate))
+		w.WriteCell(sheet, row, col+2, tax.GetExemptionVerdict(exemption.ExemptWithYearExchangeRate))
+	}
 
 	var coordsEqSumDRDs string
 	var coordsEqSumDRYs string
@@ -93,6 +158,16 @@ func writeOverviewStatement(w *util.ExcelWriter, report *tax.Report, itemTypeStr
 			coordsDTD := w.WriteAccountingCell(sheet, row, col+4, dividendReport.PaidTax.ValueWithDayExchangeRate, dividendReport.PaidTax.Currency)
 			coordsDTY := w.WriteAccountingCell(sheet, row, col+5, dividendReport.PaidTax.ValueWithYearExchangeRate, dividendReport.PaidTax.Currency)
 			row++
+			w.WriteCell(sheet, row, col, "Excess Tax (reclaimable)")
+			w.WriteAccountingCell(sheet, row, col+3, dividendReport.OriginalExcessPaidTax.ValueWithDayExchangeRate, dividendReport.OriginalExcessPaidTax.Currency)
+			w.WriteAccountingCell(sheet, row, col+4, dividendReport.ExcessPaidTax.ValueWithDayExchangeRate, dividendReport.ExcessPaidTax.Currency)
+			w.WriteAccountingCell(sheet, row, col+5, dividendReport.ExcessPaidTax.ValueWithYearExchangeRate, dividendReport.ExcessPaidTax.Currency)
+			row++
+			w.WriteCell(sheet, row, col, "Refunded Tax")
+			w.WriteAccountingCell(sheet, row, col+3, dividendReport.OriginalRefundedTax.ValueWithDayExchangeRate, dividendReport.OriginalRefundedTax.Currency)
+			w.WriteAccountingCell(sheet, row, col+4, dividendReport.RefundedTax.ValueWithDayExchangeRate, dividendReport.RefundedTax.Currency)
+			w.WriteAccountingCell(sheet, row, col+5, dividendReport.RefundedTax.ValueWithYearExchangeRate, dividendReport.RefundedTax.Currency)
+			row++
 			w.WriteCell(sheet, row, col, "Fees")
 			coordsDFO := w.WriteAccountingCell(sheet, row, col+3, dividendReport.OriginalRawRevenue.Fee.ValueWithDayExchangeRate, dividendReport.OriginalRawRevenue.Fee.Currency)
 			coordsDFD := w.WriteAccountingCell(sheet, row, col+4, dividendReport.RawRevenue.Fee.ValueWithDayExchangeRate, dividendReport.RawRevenue.Fee.Currency)
@@ -116,16 +191,94 @@ func writeOverviewStatement(w *util.ExcelWriter, report *tax.Report, itemTypeStr
 	w.WriteCell(sheet, row, col+2, "with YEAR exchange rate")
 	row++
 	w.WriteCell(sheet, row, col, "Revenue")
-	coordsDRD := w.WriteAccountingEqCell(sheet, row, col+1, coordsEqSumDRDs, report.Currency)
-	coordsDRY := w.WriteAccountingEqCell(sheet, row, col+2, coordsEqSumDRYs, report.Currency)
+	w.WriteAccountingEqCell(sheet, row, col+1, coordsEqSumDRDs, report.Currency)
+	w.WriteAccountingEqCell(sheet, row, col+2, coordsEqSumDRYs, report.Currency)
 	row++
 	w.WriteCell(sheet, row, col, "Fees")
 	w.WriteAccountingEqCell(sheet, row, col+1, coordsEqSumDFDs, report.Currency)
 	w.WriteAccountingEqCell(sheet, row, col+2, coordsEqSumDFYs, report.Currency)
 	row++
 	w.WriteCell(sheet, row, col, "Profit")
-	coordsDPD := w.WriteAccountingEqCell(sheet, row, col+1, coordsEqSumDPDs, report.Currency)
-	coordsDPY := w.WriteAccountingEqCell(sheet, row, col+2, coordsEqSumDPYs, report.Currency)
+	w.WriteAccountingEqCell(sheet, row, col+1, coordsEqSumDPDs, report.Currency)
+	w.WriteAccountingEqCell(sheet, row, col+2, coordsEqSumDPYs, report.Currency)
+
+	if len(report.DomesticDividendReports) > 0 {
+		row += 2
+		w.WriteCell(sheet, row, col, "Domestic dividends (settled by final withholding tax, not counted)")
+		w.WriteCell(sheet, row, col+1, "Broker")
+		w.WriteCell(sheet, row, col+2, "Original value")
+		w.WriteCell(sheet, row, col+3, "with DAY exchange rate")
+		w.WriteCell(sheet, row, col+4, "with YEAR exchange rate")
+		for broker, dividendReport := range report.DomesticDividendReports.GetAll() {
+			row++
+			w.WriteCell(sheet, row, col, "Revenue")
+			w.WriteCell(sheet, row, col+1, broker)
+			w.WriteAccountingCell(sheet, row, col+2, dividendReport.OriginalRawRevenue.Value.ValueWithDayExchangeRate, dividendReport.OriginalRawRevenue.Value.Currency)
+			w.WriteAccountingCell(sheet, row, col+3, dividendReport.RawRevenue.Value.ValueWithDayExchangeRate, dividendReport.RawRevenue.Value.Currency)
+			w.WriteAccountingCell(sheet, row, col+4, dividendReport.RawRevenue.Value.ValueWithYearExchangeRate, dividendReport.RawRevenue.Value.Currency)
+			row++
+			w.WriteCell(sheet, row, col, "Withheld Tax")
+			w.WriteAccountingCell(sheet, row, col+2, dividendReport.OriginalPaidTax.ValueWithDayExchangeRate, dividendReport.OriginalPaidTax.Currency)
+			w.WriteAccountingCell(sheet, row, col+3, dividendReport.PaidTax.ValueWithDayExchangeRate, dividendReport.PaidTax.Currency)
+			w.WriteAccountingCell(sheet, row, col+4, dividendReport.PaidTax.ValueWithYearExchangeRate, dividendReport.PaidTax.Currency)
+		}
+	}
+
+	if len(report.InterestReports) > 0 || len(report.DomesticInterestReports) > 0 {
+		var coordsEqSumIRDs, coordsEqSumIRYs, coordsEqSumITDs, coordsEqSumITYs string
+		row += 2
+		w.WriteCell(sheet, row, col, "Interests (details)")
+		w.WriteCell(sheet, row, col+1, "Country")
+		w.WriteCell(sheet, row, col+2, "Broker")
+		w.WriteCell(sheet, row, col+3, "Original value")
+		w.WriteCell(sheet, row, col+4, "with DAY exchange rate")
+		w.WriteCell(sheet, row, col+5, "with YEAR exchange rate")
+		for country, brokerInterestReports := range report.InterestReports {
+			for broker, interestReport := range brokerInterestReports.GetAll() {
+				row++
+				w.WriteCell(sheet, row, col, "Revenue")
+				w.WriteCell(sheet, row, col+1, country)
+				w.WriteCell(sheet, row, col+2, broker)
+				w.WriteAccountingCell(sheet, row, col+3, interestReport.OriginalRawRevenue.Value.ValueWithDayExchangeRate, interestReport.OriginalRawRevenue.Value.Currency)
+				coordsEqSumIRDs += "+" + w.WriteAccountingCell(sheet, row, col+4, interestReport.RawRevenue.Value.ValueWithDayExchangeRate, interestReport.RawRevenue.Value.Currency)
+				coordsEqSumIRYs += "+" + w.WriteAccountingCell(sheet, row, col+5, interestReport.RawRevenue.Value.ValueWithYearExchangeRate, interestReport.RawRevenue.Value.Currency)
+				row++
+				w.WriteCell(sheet, row, col, "Paid Tax")
+				w.WriteAccountingCell(sheet, row, col+3, interestReport.OriginalPaidTax.ValueWithDayExchangeRate, interestReport.OriginalPaidTax.Currency)
+				coordsEqSumITDs += "+" + w.WriteAccountingCell(sheet, row, col+4, interestReport.PaidTax.ValueWithDayExchangeRate, interestReport.PaidTax.Currency)
+				coordsEqSumITYs += "+" + w.WriteAccountingCell(sheet, row, col+5, interestReport.PaidTax.ValueWithYearExchangeRate, interestReport.PaidTax.Currency)
+				row++
+				w.WriteCell(sheet, row, col, "Fees")
+				w.WriteAccountingCell(sheet, row, col+3, interestReport.OriginalRawRevenue.Fee.ValueWithDayExchangeRate, interestReport.OriginalRawRevenue.Fee.Currency)
+				w.WriteAccountingCell(sheet, row, col+4, interestReport.RawRevenue.Fee.ValueWithDayExchangeRate, interestReport.RawRevenue.Fee.Currency)
+				w.WriteAccountingCell(sheet, row, col+5, interestReport.RawRevenue.Fee.ValueWithYearExchangeRate, interestReport.RawRevenue.Fee.Currency)
+			}
+		}
+		for broker, interestReport := range report.DomesticInterestReports.GetAll() {
+			row++
+			w.WriteCell(sheet, row, col, "Domestic Revenue (settled by final withholding tax, not counted)")
+			w.WriteCell(sheet, row, col+1, interestReport.Country)
+			w.WriteCell(sheet, row, col+2, broker)
+			w.WriteAccountingCell(sheet, row, col+3, interestReport.OriginalRawRevenue.Value.ValueWithDayExchangeRate, interestReport.OriginalRawRevenue.Value.Currency)
+			w.WriteAccountingCell(sheet, row, col+4, interestReport.RawRevenue.Value.ValueWithDayExchangeRate, interestReport.RawRevenue.Value.Currency)
+			w.WriteAccountingCell(sheet, row, col+5, interestReport.RawRevenue.Value.ValueWithYearExchangeRate, interestReport.RawRevenue.Value.Currency)
+		}
+
+		if coordsEqSumIRDs != "" {
+			row += 2
+			w.WriteCell(sheet, row, col, "Interests (summary)")
+			w.WriteCell(sheet, row, col+1, "with DAY exchange rate")
+			w.WriteCell(sheet, row, col+2, "with YEAR exchange rate")
+			row++
+			w.WriteCell(sheet, row, col, "Revenue")
+			w.WriteAccountingEqCell(sheet, row, col+1, coordsEqSumIRDs, report.Currency)
+			w.WriteAccountingEqCell(sheet, row, col+2, coordsEqSumIRYs, report.Currency)
+			row++
+			w.WriteCell(sheet, row, col, "Paid Tax")
+			w.WriteAccountingEqCell(sheet, row, col+1, coordsEqSumITDs, report.Currency)
+			w.WriteAccountingEqCell(sheet, row, col+2, coordsEqSumITYs, report.Currency)
+		}
+	}
 
 	row += 2
 	w.WriteCell(sheet, row, col, "Additional")
@@ -141,29 +294,306 @@ func writeOverviewStatement(w *util.ExcelWriter, report *tax.Report, itemTypeStr
 	coordsAFY := w.WriteAccountingCell(sheet, row, col+2, report.AdditionalRevenue.Fee.ValueWithYearExchangeRate, report.AdditionalRevenue.Fee.Currency)
 	row++
 	w.WriteCell(sheet, row, col, "Profit")
-	coordsAPD := w.WriteAccountingEqCell(sheet, row, col+1, fmt.Sprintf("%s-%s", coordsARD, coordsAFD), report.Currency)
-	coordsAPY := w.WriteAccountingEqCell(sheet, row, col+2, fmt.Sprintf("%s-%s", coordsARY, coordsAFY), report.Currency)
+	w.WriteAccountingEqCell(sheet, row, col+1, fmt.Sprintf("%s-%s", coordsARD, coordsAFD), report.Currency)
+	w.WriteAccountingEqCell(sheet, row, col+2, fmt.Sprintf("%s-%s", coordsARY, coordsAFY), report.Currency)
+
+	if report.RewardRevenue != nil && (report.RewardRevenue.ValueWithDayExchangeRate != 0 || report.RewardRevenue.ValueWithYearExchangeRate != 0) {
+		row += 2
+		w.WriteCell(sheet, row, col, "Rewards (staking, airdrops, mining)")
+		w.WriteCell(sheet, row, col+1, "with DAY exchange rate")
+		w.WriteCell(sheet, row, col+2, "with YEAR exchange rate")
+		row++
+		w.WriteCell(sheet, row, col, "Revenue")
+		w.WriteAccountingCell(sheet, row, col+1, report.RewardRevenue.ValueWithDayExchangeRate, report.RewardRevenue.Currency)
+		w.WriteAccountingCell(sheet, row, col+2, report.RewardRevenue.ValueWithYearExchangeRate, report.RewardRevenue.Currency)
+	}
+
+	// partial tax bases of income categories which are counted to totals (a loss is not offset with other categories)
+	var coordsEqSumCRDs, coordsEqSumCRYs, coordsEqSumCBDs, coordsEqSumCBYs string
+	row += 2
+	w.WriteCell(sheet, row, col, "Income categories (tax bases)")
+	w.WriteCell(sheet, row, col+1, "Revenue with DAY exchange rate")
+	w.WriteCell(sheet, row, col+2, "Revenue with YEAR exchange rate")
+	w.WriteCell(sheet, row, col+3, "Expense with DAY exchange rate")
+	w.WriteCell(sheet, row, col+4, "Expense with YEAR exchange rate")
+	w.WriteCell(sheet, row, col+5, "Tax Base with DAY exchange rate")
+	w.WriteCell(sheet, row, col+6, "Tax Base with YEAR exchange rate")
+	incomeCategoryBases := report.GetIncomeCategoryBases()
+	for _, category := range tax.IncomeCategories {
+		base, exists := incomeCategoryBases[category]
+		if !exists {
+			continue
+		}
+		row++
+		w.WriteCell(sheet, row, col, string(category))
+		coordsEqSumCRDs += "+" + w.WriteAccountingCell(sheet, row, col+1, base.Revenue.ValueWithDayExchangeRate, base.Revenue.Currency)
+		coordsEqSumCRYs += "+" + w.WriteAccountingCell(sheet, row, col+2, base.Revenue.ValueWithYearExchangeRate, base.Revenue.Currency)
+		w.WriteAccountingCell(sheet, row, col+3, base.Expense.ValueWithDayExchangeRate, base.Expense.Currency)
+		w.WriteAccountingCell(sheet, row, col+4, base.Expense.ValueWithYearExchangeRate, base.Expense.Currency)
+		coordsEqSumCBDs += "+" + w.WriteAccountingCell(sheet, row, col+5, base.Base.ValueWithDayExchangeRate, base.Base.Currency)
+		coordsEqSumCBYs += "+" + w.WriteAccountingCell(sheet, row, col+6, base.Base.ValueWithYearExchangeRate, base.Base.Currency)
+	}
+
+	if oversoldOperations := report.SellOperations.GetOversold(); len(oversoldOperations) > 0 {
+		row += 2
+		w.WriteCell(sheet, row, col, "Oversold sales (not covered by buys)")
+		w.WriteCell(sheet, row, col+1, "Date")
+		w.WriteCell(sheet, row, col+2, "Missing quantity")
+		w.WriteCell(sheet, row, col+3, "Sheet")
+		w.WriteCell(sheet, row, col+4, "Row")
+		for _, sellOp := range oversoldOperations {
+			row++
+			w.WriteCell(sheet, row, col, sellOp.SellItem.Name)
+			w.WriteDateCell(sheet, row, col+1, sellOp.SellItem.Date)
+			w.WriteCell(sheet, row, col+2, sellOp.OversoldQuantity)
+			w.WriteCell(sheet, row, col+3, sellOp.SellItem.SheetName)
+			w.WriteCell(sheet, row, col+4, sellOp.SellItem.SheetRow)
+		}
+	}
+
+	if len(report.PaidTaxMismatches) > 0 {
+		row += 2
+		w.WriteCell(sheet, row, col, "Paid tax mismatches (PAID TAX vs. AMOUNT - RECEIVED)")
+		w.WriteCell(sheet, row, col+1, "Date")
+		w.WriteCell(sheet, row, col+2, "Sheet")
+		w.WriteCell(sheet, row, col+3, "Row")
+		w.WriteCell(sheet, row, col+4, "PAID TAX")
+		w.WriteCell(sheet, row, col+5, "Withheld (derived)")
+		w.WriteCell(sheet, row, col+6, "Mismatch")
+		w.WriteCell(sheet, row, col+7, "Resolution")
+		for _, item := range report.PaidTaxMismatches {
+			row++
+			w.WriteCell(sheet, row, col, item.Name)
+			w.WriteDateCell(sheet, row, col+1, item.Date)
+			w.WriteCell(sheet, row, col+2, item.SheetName)
+			w.WriteCell(sheet, row, col+3, item.SheetRow)
+			w.WriteAccountingCell(sheet, row, col+4, item.ExplicitPaidTax, item.Currency)
+			w.WriteAccountingCell(sheet, row, col+5, item.ExplicitPaidTax+item.PaidTaxMismatch, item.Currency)
+			w.WriteAccountingCell(sheet, row, col+6, item.PaidTaxMismatch, item.Currency)
+			w.WriteCell(sheet, row, col+7, item.GetPaidTaxMismatchResolution())
+		}
+	}
+
+	if report.Rules != nil && report.Rules.RuleSet != nil {
+		row += 2
+		writeRuleSet(w, sheet, row, col, report.Rules.RuleSet, report.Currency)
+	}
 
 	row, col = 0, 0
 	w.WriteCell(sheet, row, col, "Year")
 	w.WriteCell(sheet, row, col+1, report.Year.Year())
+	w.WriteCell(sheet, row, col+2, "Purchase price method")
+	w.WriteCell(sheet, row, col+3, tax.GetCostBasisStrategyDescription(report.CostBasisStrategy))
+	w.WriteCell(sheet, row, col+4, "Buys matched")
+	if report.PerBrokerPools {
+		w.WriteCell(sheet, row, col+5, "per broker")
+	} else {
+		w.WriteCell(sheet, row, col+5, "across brokers")
+	}
 	row++
 	w.WriteCell(sheet, row, col+1, "with DAY exchange rate")
 	w.WriteCell(sheet, row, col+2, "with YEAR exchange rate")
 	row++
 	w.WriteCell(sheet, row, col, "Total Revenue")
-	// revenue - Time Tested revenue + Dividend revenue + Dividend (to pay tax) revenue + Additional revenue
-	w.WriteAccountingEqCell(sheet, row, col+1, fmt.Sprintf("(%s-%s)+%s+%s", coordsSRD, coordsTSRD, coordsDRD, coordsARD), report.Currency)
-	w.WriteAccountingEqCell(sheet, row, col+2, fmt.Sprintf("(%s-%s)+%s+%s", coordsSRY, coordsTSRY, coordsDRY, coordsARY), report.Currency)
+	// taxed revenue of all income categories
+	w.WriteAccountingEqCell(sheet, row, col+1, coordsEqSumCRDs, report.Currency)
+	w.WriteAccountingEqCell(sheet, row, col+2, coordsEqSumCRYs, report.Currency)
+	row++
+	w.WriteCell(sheet, row, col, "Total Tax Base")
+	// sum of tax bases of income categories (each is never negative)
+	w.WriteAccountingEqCell(sheet, row, col+1, coordsEqSumCBDs, report.Currency)
+	w.WriteAccountingEqCell(sheet, row, col+2, coordsEqSumCBYs, report.Currency)
+
+	return nil
+}
+
+func writeTaxLiability(w *util.ExcelWriter, liability *tax.TaxLiability) error {
+	sheet := "Tax liability"
+	// Create a new sheet.
+	w.File.NewSheet(sheet)
+
+	row, col := 0, 0
+	w.WriteCell(sheet, row, col, "Tax liability")
+	w.WriteCell(sheet, row, col+1, "with DAY exchange rate")
+	w.WriteCell(sheet, row, col+2, "with YEAR exchange rate")
+	row++
+	w.WriteCell(sheet, row, col, "Capital income base (§8)")
+	w.WriteAccountingCell(sheet, row, col+1, liability.CapitalIncomeBase.ValueWithDayExchangeRate, liability.CapitalIncomeBase.Currency)
+	w.WriteAccountingCell(sheet, row, col+2, liability.CapitalIncomeBase.ValueWithYearExchangeRate, liability.CapitalIncomeBase.Currency)
+	row++
+	w.WriteCell(sheet, row, col, "Other income base (§10)")
+	w.WriteAccountingCell(sheet, row, col+1, liability.OtherIncomeBase.ValueWithDayExchangeRate, liability.OtherIncomeBase.Currency)
+	w.WriteAccountingCell(sheet, row, col+2, liability.OtherIncomeBase.ValueWithYearExchangeRate, liability.OtherIncomeBase.Currency)
+	for _, category := range tax.IncomeCategories {
+		if category == tax.CAPITAL_INCOME {
+			continue
+		}
+		base := liability.IncomeCategoryBases[category]
+		row++
+		w.WriteCell(sheet, row, col, " - "+string(category))
+		w.WriteAccountingCell(sheet, row, col+1, base.Base.ValueWithDayExchangeRate, base.Base.Currency)
+		w.WriteAccountingCell(sheet, row, col+2, base.Base.ValueWithYearExchangeRate, base.Base.Currency)
+	}
+	row++
+	if liability.AdditionalTaxBaseSupplied {
+		w.WriteCell(sheet, row, col, "Additional tax base (user supplied)")
+	} else {
+		w.WriteCell(sheet, row, col, "Additional tax base (not supplied for this year - left out)")
+	}
+	w.WriteAccountingCell(sheet, row, col+1, liability.AdditionalTaxBase, liability.TotalTaxBase.Currency)
+	w.WriteAccountingCell(sheet, row, col+2, liability.AdditionalTaxBase, liability.TotalTaxBase.Currency)
+	row++
+	w.WriteCell(sheet, row, col, "Total tax base (rounded)")
+	w.WriteAccountingCell(sheet, row, col+1, liability.TotalTaxBase.ValueWithDayExchangeRate, liability.TotalTaxBase.Currency)
+	w.WriteAccountingCell(sheet, row, col+2, liability.TotalTaxBase.ValueWithYearExchangeRate, liability.TotalTaxBase.Currency)
+	row++
+	w.WriteCell(sheet, row, col, "Tax")
+	w.WriteAccountingCell(sheet, row, col+1, liability.Tax.ValueWithDayExchangeRate, liability.Tax.Currency)
+	w.WriteAccountingCell(sheet, row, col+2, liability.Tax.ValueWithYearExchangeRate, liability.Tax.Currency)
+	row++
+	w.WriteCell(sheet, row, col, "Foreign tax credit")
+	w.WriteAccountingCell(sheet, row, col+1, liability.ForeignTaxCredit.ValueWithDayExchangeRate, liability.ForeignTaxCredit.Currency)
+	w.WriteAccountingCell(sheet, row, col+2, liability.ForeignTaxCredit.ValueWithYearExchangeRate, liability.ForeignTaxCredit.Currency)
+	row++
+	w.WriteCell(sheet, row, col, "Tax due")
+	w.WriteAccountingCell(sheet, row, col+1, liability.TaxDue.ValueWithDayExchangeRate, liability.TaxDue.Currency)
+	w.WriteAccountingCell(sheet, row, col+2, liability.TaxDue.ValueWithYearExchangeRate, liability.TaxDue.Currency)
 	row++
-	w.WriteCell(sheet, row, col, "Total Profit")
-	// profit - Time Tested profit + Dividend Profit + Dividend (to pay tax) profit + Additional profit
-	w.WriteAccountingEqCell(sheet, row, col+1, fmt.Sprintf("(%s-%s)+%s+%s", coordsSPD, coordsTSPD, coordsDPD, coordsAPD), report.Currency)
-	w.WriteAccountingEqCell(sheet, row, col+2, fmt.Sprintf("(%s-%s)+%s+%s", coordsSPY, coordsTSPY, coordsDPY, coordsAPY), report.Currency)
+	w.WriteCell(sheet, row, col, "Tax due caused by input files")
+	w.WriteAccountingCell(sheet, row, col+1, liability.ReportsTaxDue.ValueWithDayExchangeRate, liability.ReportsTaxDue.Currency)
+	w.WriteAccountingCell(sheet, row, col+2, liability.ReportsTaxDue.ValueWithYearExchangeRate, liability.ReportsTaxDue.Currency)
+	return nil
+}
+
+// rows of attachment no. 3 (Příloha č. 3), columns with DAY and YEAR exchange rate per country
+func writeForeignTaxCredits(w *util.ExcelWriter, credits tax.ForeignTaxCredits) error {
+	sheet := "Attachment 3 - Foreign tax"
+	// Create a new sheet.
+	w.File.NewSheet(sheet)
+
+	row, col := 0, 0
+	w.WriteCell(sheet, row, col, "Row")
+	w.WriteCell(sheet, row, col+1, "Description")
+	rows := []struct {
+		no          string
+		description string
+	}{
+		{"", "Country"},
+		{"321", "Income from foreign sources"},
+		{"322", "Expense"},
+		{"323", "Positive difference of income and expense"},
+		{"324", "Tax paid abroad"},
+		{"325", "Coefficient (%)"},
+		{"326", "Max creditable tax"},
+		{"327", "Credited tax"},
+		{"328", "Not credited tax"},
+	}
+	for i, r := range rows {
+		w.WriteCell(sheet, row+1+i, col, r.no)
+		w.WriteCell(sheet, row+1+i, col+1, r.description)
+	}
 
+	for i, credit := range credits {
+		colD, colY := col+2+2*i, col+3+2*i
+		difference := credit.GetPositiveDifference()
+		w.WriteCell(sheet, row, colD, "with DAY exchange rate")
+		w.WriteCell(sheet, row, colY, "with YEAR exchange rate")
+		w.WriteCell(sheet, row+1, colD, credit.Country)
+		w.WriteCell(sheet, row+1, colY, credit.Country)
+		for j, value := range []*tax.AccountingValue{credit.Income, credit.Expense, difference, credit.PaidTax} {
+			w.WriteAccountingCell(sheet, row+2+j, colD, value.ValueWithDayExchangeRate, value.Currency)
+			w.WriteAccountingCell(sheet, row+2+j, colY, value.ValueWithYearExchangeRate, value.Currency)
+		}
+		w.WriteFloatNumberCell(sheet, row+6, colD, credit.CoefficientWithDayExchangeRate)
+		w.WriteFloatNumberCell(sheet, row+6, colY, credit.CoefficientWithYearExchangeRate)
+		for j, value := range []*tax.AccountingValue{credit.MaxCreditable, credit.Creditable, credit.NonCreditable} {
+			w.WriteAccountingCell(sheet, row+7+j, colD, value.ValueWithDayExchangeRate, value.Currency)
+			w.WriteAccountingCell(sheet, row+7+j, colY, value.ValueWithYearExchangeRate, value.Currency)
+		}
+	}
 	return nil
 }
 
+func writeNotificationObligation(w *util.ExcelWriter, statement *Statement) error {
+	obligation := statement.NotificationObligation
+	sheet := "Notification obligation"
+	// Create a new sheet.
+	w.File.NewSheet(sheet)
+
+	row, col := 0, 0
+	w.WriteCell(sheet, row, col, "Exempted revenue notification")
+	w.WriteCell(sheet, row, col+1, "with DAY exchange rate")
+	w.WriteCell(sheet, row, col+2, "with YEAR exchange rate")
+	row++
+	w.WriteCell(sheet, row, col, "Limit")
+	w.WriteAccountingCell(sheet, row, col+1, obligation.Limit, obligation.ExemptRevenue.Currency)
+	w.WriteAccountingCell(sheet, row, col+2, obligation.Limit, obligation.ExemptRevenue.Currency)
+	row++
+	w.WriteCell(sheet, row, col, "Exempted Revenue")
+	w.WriteAccountingCell(sheet, row, col+1, obligation.ExemptRevenue.ValueWithDayExchangeRate, obligation.ExemptRevenue.Currency)
+	w.WriteAccountingCell(sheet, row, col+2, obligation.ExemptRevenue.ValueWithYearExchangeRate, obligation.ExemptRevenue.Currency)
+	row++
+	w.WriteCell(sheet, row, col, "Notification Required")
+	w.WriteCell(sheet, row, col+1, obligation.RequiredWithDayExchangeRate)
+	w.WriteCell(sheet, row, col+2, obligation.RequiredWithYearExchangeRate)
+
+	row += 2
+	w.WriteCell(sheet, row, col, "Contributing sales")
+	w.WriteCell(sheet, row, col+1, "Type")
+	w.WriteCell(sheet, row, col+2, "Sell Date")
+	w.WriteCell(sheet, row, col+3, "Time Tested Revenue (Day ExR)")
+	w.WriteCell(sheet, row, col+4, "Time Tested Revenue (Year ExR)")
+	for _, typedReport := range []struct {
+		report         *tax.Report
+		itemTypeString string
+	}{{statement.StockReport, "Stocks"}, {statement.CryptoReport, "Cryptos"}} {
+		if typedReport.report == nil {
+			continue
+		}
+		for _, sellOp := range typedReport.report.SellOperations.GetTimeTested() {
+			row++
+			revenue := sellOp.GetTimeTestedRevenue()
+			w.WriteCell(sheet, row, col, sellOp.SellItem.Name)
+			w.WriteCell(sheet, row, col+1, typedReport.itemTypeString)
+			w.WriteDateCell(sheet, row, col+2, sellOp.SellItem.Date)
+			w.WriteAccountingCell(sheet, row, col+3, revenue.ValueWithDayExchangeRate, revenue.Currency)
+			w.WriteAccountingCell(sheet, row, col+4, revenue.ValueWithYearExchangeRate, revenue.Currency)
+		}
+	}
+	return nil
+}
+
+func writeRuleSet(w *util.ExcelWriter, sheet string, row, col int, ruleSet *rules.RuleSet, currency *util.Currency) {
+	w.WriteCell(sheet, row, col, "Applied tax rules")
+	w.WriteCell(sheet, row, col+1, fmt.Sprintf("valid since %d", ruleSet.ValidSince))
+	row++
+	w.WriteCell(sheet, row, col, "Securities time test (months)")
+	w.WriteCell(sheet, row, col+1, ruleSet.SecuritiesTimeTestMonths)
+	if ruleSet.LegacySecuritiesTimeTestMonths > 0 {
+		row++
+		w.WriteCell(sheet, row, col, "Securities acquired before "+ruleSet.LegacySecuritiesAcquiredBefore.Format("02.01.2006")+" time test (months)")
+		w.WriteCell(sheet, row, col+1, ruleSet.LegacySecuritiesTimeTestMonths)
+	}
+	row++
+	w.WriteCell(sheet, row, col, "Securities revenue exemption limit")
+	w.WriteAccountingCell(sheet, row, col+1, ruleSet.SecuritiesRevenueExemptionLimit, currency)
+	row++
+	w.WriteCell(sheet, row, col, "Crypto time test (months)")
+	w.WriteCell(sheet, row, col+1, ruleSet.CryptoTimeTestMonths)
+	row++
+	w.WriteCell(sheet, row, col, "Crypto revenue exemption limit")
+	w.WriteAccountingCell(sheet, row, col+1, ruleSet.CryptoRevenueExemptionLimit, currency)
+	row++
+	w.WriteCell(sheet, row, col, "Other income filing limit")
+	w.WriteAccountingCell(sheet, row, col+1, ruleSet.OtherIncomeFilingLimit, currency)
+	row++
+	w.WriteCell(sheet, row, col, "Exempt revenue reporting limit")
+	w.WriteAccountingCell(sheet, row, col+1, ruleSet.ExemptRevenueReportingLimit, currency)
+	row++
+	w.WriteCell(sheet, row, col, "Time tested exemption cap")
+	w.WriteAccountingCell(sheet, row, col+1, ruleSet.TimeTestedExemptionCap, currency)
+}
+
 func ExportToExcel(statement *Statement, exportFilePath string) error {
 	w := util.NewExcelWriter()
 
@@ -178,6 +608,26 @@ func ExportToExcel(statement *Statement, exportFilePath string) error {
 			return fmt.Errorf("cannot write crypto overview statement for year '%v': %v", w, err)
 		}
 	}
+	if statement.DerivativeReport != nil {
+		if err := writeOverviewStatement(w, statement.DerivativeReport, "Derivatives"); err != nil {
+			return fmt.Errorf("cannot write derivative overview statement for year '%v': %v", w, err)
+		}
+	}
+	if statement.TaxLiability != nil {
+		if err := writeTaxLiability(w, statement.TaxLiability); err != nil {
+			return fmt.Errorf("cannot write tax liability for year '%v': %v", statement.Year, err)
+		}
+	}
+	if statement.TaxLiability != nil && len(statement.TaxLiability.ForeignTaxCredits) > 0 {
+		if err := writeForeignTaxCredits(w, statement.TaxLiability.ForeignTaxCredits); err != nil {
+			return fmt.Errorf("cannot write foreign tax credits for year '%v': %v", statement.Year, err)
+		}
+	}
+	if statement.NotificationObligation != nil {
+		if err := writeNotificationObligation(w, statement); err != nil {
+			return fmt.Errorf("cannot write notification obligation for year '%v': %v", statement.Year, err)
+		}
+	}
 	// write sales log
 	if statement.StockReport != nil {
 		if err := salesLogToExcel(w, statement.StockReport.SellOperations, "Stocks"); err != nil {
@@ -189,6 +639,11 @@ func ExportToExcel(statement *Statement, exportFilePath string) error {
 			return fmt.Errorf("cannot write crypto sales log for year '%v': %v", w, err)
 		}
 	}
+	if statement.DerivativeReport != nil {
+		if err := closedPositionsLogToExcel(w, statement.DerivativeReport.ClosedPositions, "Derivatives"); err != nil {
+			return fmt.Errorf("cannot write derivative positions log for year '%v': %v", w, err)
+		}
+	}
 	// Delete "Sheet1"
 	w.File.DeleteSheet(w.File.GetSheetName(0))
 	if err := w.File.SaveAs(exportFilePath); err != nil {
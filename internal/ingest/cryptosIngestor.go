@@ -34,6 +34,25 @@ var (
 		"BROKER":     7,
 		"CURRENCY":   8,
 	}
+	cryptoSwapTblLegend = map[string]int{
+		"FROM CRYPTO":   0,
+		"DATE":          1,
+		"FROM QUANTITY": 2,
+		"TO CRYPTO":     3,
+		"TO QUANTITY":   4,
+		"VALUE":         5,
+		"FEE":           6,
+		"BROKER":        7,
+		"CURRENCY":      8,
+	}
+	cryptoRewardTblLegend = map[string]int{
+		"CRYPTO":   0,
+		"DATE":     1,
+		"QUANTITY": 2,
+		"VALUE":    3,
+		"BROKER":   4,
+		"CURRENCY": 5,
+	}
 )
 
 func newCryptoBuyItem(row []string) (_ *TransactionLogItem, err error) {
@@ -116,6 +135,126 @@ func newCryptoSellItem(row []string) (_ *TransactionLogItem, err error) {
 	return validateCryptoSellItem(&item)
 }
 
+// Creates disposal (sell) of a swap valued at market value of the swap with its acquisition (buy) as related item.
+// The fee is assigned to the disposal only.
+func newCryptoSwapItem(row []string) (_ *TransactionLogItem, err error) {
+	item := TransactionLogItem{
+		Name:      row[cryptoSwapTblLegend["FROM CRYPTO"]],
+		Broker:    row[cryptoSwapTblLegend["BROKER"]],
+		Operation: SELL,
+	}
+	acquisition := TransactionLogItem{
+		Name:      row[cryptoSwapTblLegend["TO CRYPTO"]],
+		Broker:    item.Broker,
+		Operation: BUY,
+	}
+
+	if rawDate, err := strconv.ParseFloat(row[cryptoSwapTblLegend["DATE"]], 64); err != nil {
+		return nil, fmt.Errorf("raw date is not a number: %v", err)
+	} else if item.Date, err = excel.ExcelDateToTime(rawDate, false); err != nil {
+		return nil, fmt.Errorf("date has invalid format: %v", err)
+	}
+	acquisition.Date = item.Date
+	if item.Quantity, err = strconv.ParseFloat(row[cryptoSwapTblLegend["FROM QUANTITY"]], 64); err != nil {
+		return nil, fmt.Errorf("from quantity is not a number: %v", err)
+	}
+	if acquisition.Quantity, err = strconv.ParseFloat(row[cryptoSwapTblLegend["TO QUANTITY"]], 64); err != nil {
+		return nil, fmt.Errorf("to quantity is not a number: %v", err)
+	}
+	if item.Quantity <= 0 || acquisition.Quantity <= 0 {
+		return nil, fmt.Errorf("quantities '%v' and '%v' are not positive", item.Quantity, acquisition.Quantity)
+	}
+	if item.BrokerAmount, err = strconv.ParseFloat(row[cryptoSwapTblLegend["VALUE"]], 64); err != nil {
+		return nil, fmt.Errorf("value is not a number: %v", err)
+	}
+	if item.Fee, err = strconv.ParseFloat(row[cryptoSwapTblLegend["FEE"]], 64); err != nil {
+		return nil, fmt.Errorf("fee is not a number: %v", err)
+	}
+	item.BankAmount = item.BrokerAmount - item.Fee
+	item.OriginalBankAmount = item.BankAmount
+	item.ItemPrice = item.BrokerAmount / item.Quantity
+	acquisition.BrokerAmount = item.BrokerAmount
+	acquisition.BankAmount = acquisition.BrokerAmount
+	acquisition.OriginalBankAmount = acquisition.BankAmount
+	acquisition.ItemPrice = acquisition.BrokerAmount / acquisition.Quantity
+	if item.Currency, err = util.GetCurrencyByName(row[cryptoSwapTblLegend["CURRENCY"]]); err != nil {
+		return nil, fmt.Errorf("currency format problem: %v", err)
+	}
+	if item.DayExchangeRate, err = util.GetCzkExchangeRateInDay(item.Date, *item.Currency); err != nil {
+		return nil, fmt.Errorf("cannot get exchange rate for %v from %v: %v", item.Currency, item.Date, err)
+	}
+	if item.YearExchangeRate, err = util.GetCzkExchangeRateInYear(item.Date, *item.Currency); err != nil {
+		return nil, fmt.Errorf("cannot get year exchange rate for %v from %v: %v", item.Currency, item.Date, err)
+	}
+	acquisition.Currency = item.Currency
+	acquisition.DayExchangeRate = item.DayExchangeRate
+	acquisition.YearExchangeRate = item.YearExchangeRate
+
+	if _, err = validateCryptoSellItem(&item); err != nil {
+		return nil, err
+	}
+	if _, err = validateCryptoBuyItem(&acquisition); err != nil {
+		return nil, err
+	}
+	item.RelatedItem = &acquisition
+	return &item, nil
+}
+
+// Creates reward (staking, airdrop, mining) income valued at fair value with acquisition (buy) of the received
+// crypto as related item.
+func newCryptoRewardItem(row []string) (_ *TransactionLogItem, err error) {
+	item := TransactionLogItem{
+		Name:      row[cryptoRewardTblLegend["CRYPTO"]],
+		Broker:    row[cryptoRewardTblLegend["BROKER"]],
+		Operation: REWARD,
+	}
+
+	if rawDate, err := strconv.ParseFloat(row[cryptoRewardTblLegend["DATE"]], 64); err != nil {
+		return nil, fmt.Errorf("raw date is not a number: %v", err)
+	} else if item.Date, err = excel.ExcelDateToTime(rawDate, false); err != nil {
+		return nil, fmt.Errorf("date has invalid format: %v", err)
+	}
+	if item.Quantity, err = strconv.ParseFloat(row[cryptoRewardTblLegend["QUANTITY"]], 64); err != nil {
+		return nil, fmt.Errorf("quantity is not a number: %v", err)
+	} else if item.Quantity <= 0 {
+		return nil, fmt.Errorf("quantity '%v' is not positive", item.Quantity)
+	}
+	if item.BrokerAmount, err = strconv.ParseFloat(row[cryptoRewardTblLegend["VALUE"]], 64); err != nil {
+		return nil, fmt.Errorf("value is not a number: %v", err)
+	} else if item.BrokerAmount < 0 {
+		return nil, fmt.Errorf("value '%v' is negative", item.BrokerAmount)
+	}
+	item.BankAmount = item.BrokerAmount
+	item.OriginalBankAmount = item.BankAmount
+	item.ItemPrice = item.BrokerAmount / item.Quantity
+	if item.Currency, err = util.GetCurrencyByName(row[cryptoRewardTblLegend["CURRENCY"]]); err != nil {
+		return nil, fmt.Errorf("currency format problem: %v", err)
+	}
+	if item.DayExchangeRate, err = util.GetCzkExchangeRateInDay(item.Date, *item.Currency); err != nil {
+		return nil, fmt.Errorf("cannot get exchange rate for %v from %v: %v", item.Currency, item.Date, err)
+	}
+	if item.YearExchangeRate, err = util.GetCzkExchangeRateInYear(item.Date, *item.Currency); err != nil {
+		return nil, fmt.Errorf("cannot get year exchange rate for %v from %v: %v", item.Currency, item.Date, err)
+	}
+
+	// received crypto is acquired for the income value
+	acquisition := item
+	acquisition.Operation = BUY
+	item.RelatedItem = &acquisition
+	return &item, nil
+}
+
+// returns related acquisitions (purchases) of swaps or rewards
+func getRelatedAcquisitions(items TransactionLogItems) (purchases TransactionLogItems) {
+	for _, item := range items {
+		acquisition := item.RelatedItem
+		acquisition.SheetName = item.SheetName
+		acquisition.SheetRow = item.SheetRow
+		purchases = append(purchases, acquisition)
+	}
+	return
+}
+
 func validateCryptoBuyItem(item *TransactionLogItem) (_ *TransactionLogItem, err error) {
 	if !util.LeqWithTolerance(item.BrokerAmount, item.BankAmount, 0.0001) {
 		return nil, fmt.Errorf("Broker amount (AMOUNT) is greater than Bank amount (PAID) for item '%v'", item)
@@ -165,6 +304,29 @@ func ProcessCryptos(filePath string) (_ *TransactionLog, err error) {
 	}
 	log.Infof("%ss: Ingested Sales (count: %d)", CryptoItemType, len(transactions.Sales))
 
+	log.Infof("%ss: Ingesting Transfers", CryptoItemType)
+	if transactions.Transfers, err = processOptionalSheet(f, "TRANSFER", TRANSFER_TBL_LEGEND, newTransferItem); err != nil {
+		log.Errorf("%ss: %v", CryptoItemType, err)
+	}
+	log.Infof("%ss: Ingested Transfers (count: %d)", CryptoItemType, len(transactions.Transfers))
+
+	log.Infof("%ss: Ingesting Swaps", CryptoItemType)
+	if swaps, err := processOptionalSheet(f, "SWAP", cryptoSwapTblLegend, newCryptoSwapItem); err != nil {
+		log.Errorf("%ss: %v", CryptoItemType, err)
+	} else {
+		// disposal of a swap is a sell
+		transactions.Sales = append(transactions.Sales, swaps...)
+		transactions.Purchases = append(transactions.Purchases, getRelatedAcquisitions(swaps)...)
+		log.Infof("%ss: Ingested Swaps (count: %d)", CryptoItemType, len(swaps))
+	}
+
+	log.Infof("%ss: Ingesting Rewards", CryptoItemType)
+	if transactions.Rewards, err = processOptionalSheet(f, "REWARD", cryptoRewardTblLegend, newCryptoRewardItem); err != nil {
+		log.Errorf("%ss: %v", CryptoItemType, err)
+	}
+	transactions.Purchases = append(transactions.Purchases, getRelatedAcquisitions(transactions.Rewards)...)
+	log.Infof("%ss: Ingested Rewards (count: %d)", CryptoItemType, len(transactions.Rewards))
+
 	log.Infof("%ss: Ingesting Additional Incomes", CryptoItemType)
 	if transactions.AdditionalIncomes, err = processSheet(f, "ADDITIONAL INCOME", ADDITIONAL_INCOME_TBL_LEGEND, newAdditionalIncomeItem); err != nil {
 		log.Errorf("%ss: %v", CryptoItemType, err)
@@ -0,0 +1,47 @@
+package ingest
+
+import (
+	"testing"
+)
+
+func TestNewCryptoSwapItem(t *testing.T) {
+	// 0.5 BTC -> 8 ETH valued 500,000 CZK with fee 1,000 CZK (date 1.6.2025)
+	row := []string{"BTC", "45809", "0.5", "ETH", "8", "500000", "1000", "broker", "CZK"}
+	swap, err := newCryptoSwapItem(row)
+	if err != nil {
+		t.Fatalf("newCryptoSwapItem() error = %v", err)
+	}
+	purchases := getRelatedAcquisitions(TransactionLogItems{swap})
+	if len(purchases) != 1 {
+		t.Fatalf("getRelatedAcquisitions() = %v, want single buy", purchases)
+	}
+	sell, buy := swap, purchases[0]
+	if sell.Operation != SELL || sell.Name != "BTC" || sell.Quantity != 0.5 || sell.BrokerAmount != 500_000 || sell.Fee != 1_000 || sell.BankAmount != 499_000 {
+		t.Errorf("disposal = %+v, want sell of 0.5 BTC for 500000 with fee 1000", sell)
+	}
+	if buy.Operation != BUY || buy.Name != "ETH" || buy.Quantity != 8 || buy.BrokerAmount != 500_000 || buy.Fee != 0 || buy.BankAmount != 500_000 {
+		t.Errorf("acquisition = %+v, want buy of 8 ETH for 500000 without fee", buy)
+	}
+	if !buy.Date.Equal(sell.Date) {
+		t.Errorf("acquisition date = %v, want %v", buy.Date, sell.Date)
+	}
+}
+
+func TestNewCryptoRewardItem(t *testing.T) {
+	// staking reward of 0.1 ETH valued 6,000 CZK (date 1.6.2025)
+	row := []string{"ETH", "45809", "0.1", "6000", "broker", "CZK"}
+	reward, err := newCryptoRewardItem(row)
+	if err != nil {
+		t.Fatalf("newCryptoRewardItem() error = %v", err)
+	}
+	if reward.Operation != REWARD || reward.BrokerAmount != 6_000 {
+		t.Errorf("reward = %+v, want income 6000", reward)
+	}
+	purchases := getRelatedAcquisitions(TransactionLogItems{reward})
+	if len(purchases) != 1 {
+		t.Fatalf("getRelatedAcquisitions() = %v, want single buy", purchases)
+	}
+	if buy := purchases[0]; buy.Operation != BUY || buy.Name != "ETH" || buy.Quantity != 0.1 || buy.BankAmount != 6_000 || buy.Fee != 0 || !buy.Date.Equal(reward.Date) {
+		t.Errorf("acquisition = %+v, want buy of 0.1 ETH for 6000", buy)
+	}
+}
@@ -0,0 +1,148 @@
+package ingest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/marty-cz/czech-tax-calculator/internal/util"
+	log "github.com/sirupsen/logrus"
+	excel "github.com/xuri/excelize/v2"
+)
+
+const DerivativeItemType string = "derivative"
+
+var (
+	derivativeTradeTblLegend = map[string]int{
+		"CONTRACT":   0,
+		"TYPE":       1,
+		"DATE":       2,
+		"ACTION":     3,
+		"QUANTITY":   4,
+		"PRICE":      5,
+		"MULTIPLIER": 6,
+		"FEE":        7,
+		"BROKER":     8,
+		"CURRENCY":   9,
+		"UNDERLYING": 10,
+	}
+	derivativeContractTypes = map[string]ContractType{
+		"CALL":   CALL,
+		"PUT":    PUT,
+		"FUTURE": FUTURE,
+		"CFD":    CFD,
+	}
+	derivativeActions = map[string]TransactionType{
+		"BUY":        BUY,
+		"SELL":       SELL,
+		"EXPIRY":     EXPIRY,
+		"ASSIGNMENT": ASSIGNMENT,
+		"EXERCISE":   ASSIGNMENT,
+	}
+)
+
+// BUY/SELL opens or closes (long or short) position, EXPIRY and ASSIGNMENT (or EXERCISE) close option position
+func newDerivativeTradeItem(row []string) (_ *TransactionLogItem, err error) {
+	item := TransactionLogItem{
+		Name:       strings.TrimSpace(row[derivativeTradeTblLegend["CONTRACT"]]),
+		Broker:     row[derivativeTradeTblLegend["BROKER"]],
+		Underlying: getOptionalCell(row, derivativeTradeTblLegend["UNDERLYING"]),
+	}
+
+	var exists bool
+	contractType := strings.ToUpper(strings.TrimSpace(row[derivativeTradeTblLegend["TYPE"]]))
+	if item.ContractType, exists = derivativeContractTypes[contractType]; !exists {
+		return nil, fmt.Errorf("unsupported contract type '%s'", contractType)
+	}
+	action := strings.ToUpper(strings.TrimSpace(row[derivativeTradeTblLegend["ACTION"]]))
+	if item.Operation, exists = derivativeActions[action]; !exists {
+		return nil, fmt.Errorf("unsupported action '%s'", action)
+	}
+	if rawDate, err := strconv.ParseFloat(row[derivativeTradeTblLegend["DATE"]], 64); err != nil {
+		return nil, fmt.Errorf("raw date is not a number: %v", err)
+	} else if item.Date, err = excel.ExcelDateToTime(rawDate, false); err != nil {
+		return nil, fmt.Errorf("date has invalid format: %v", err)
+	}
+	if item.Quantity, err = strconv.ParseFloat(row[derivativeTradeTblLegend["QUANTITY"]], 64); err != nil {
+		return nil, fmt.Errorf("quantity is not a number: %v", err)
+	} else if item.Quantity <= 0 {
+		return nil, fmt.Errorf("quantity '%v' is not positive", item.Quantity)
+	}
+	if price := getOptionalCell(row, derivativeTradeTblLegend["PRICE"]); price != "" {
+		if item.ItemPrice, err = strconv.ParseFloat(price, 64); err != nil {
+			return nil, fmt.Errorf("price is not a number: %v", err)
+		}
+	}
+	if item.Multiplier, err = strconv.ParseFloat(row[derivativeTradeTblLegend["MULTIPLIER"]], 64); err != nil {
+		return nil, fmt.Errorf("multiplier is not a number: %v", err)
+	} else if item.Multiplier <= 0 {
+		return nil, fmt.Errorf("multiplier '%v' is not positive", item.Multiplier)
+	}
+	if fee := getOptionalCell(row, derivativeTradeTblLegend["FEE"]); fee != "" {
+		if item.Fee, err = strconv.ParseFloat(fee, 64); err != nil {
+			return nil, fmt.Errorf("fee is not a number: %v", err)
+		}
+	}
+	if item.Currency, err = util.GetCurrencyByName(row[derivativeTradeTblLegend["CURRENCY"]]); err != nil {
+		return nil, fmt.Errorf("currency format problem: %v", err)
+	}
+	if item.DayExchangeRate, err = util.GetCzkExchangeRateInDay(item.Date, *item.Currency); err != nil {
+		return nil, fmt.Errorf("cannot get day exchange rate for %v from %v: %v", item.Currency, item.Date, err)
+	}
+	if item.YearExchangeRate, err = util.GetCzkExchangeRateInYear(item.Date, *item.Currency); err != nil {
+		return nil, fmt.Errorf("cannot get year exchange rate for %v from %v: %v", item.Currency, item.Date, err)
+	}
+	item.BrokerAmount = item.Quantity * item.ItemPrice * item.Multiplier
+	if item.Operation == BUY {
+		item.BankAmount = item.BrokerAmount + item.Fee
+	} else {
+		item.BankAmount = item.BrokerAmount - item.Fee
+	}
+	item.OriginalBankAmount = item.BankAmount
+	return validateDerivativeTradeItem(&item)
+}
+
+func validateDerivativeTradeItem(item *TransactionLogItem) (_ *TransactionLogItem, err error) {
+	if item.Name == "" {
+		return nil, fmt.Errorf("missing contract of item '%v'", item)
+	}
+	switch item.Operation {
+	case EXPIRY, ASSIGNMENT:
+		if !item.ContractType.IsOption() {
+			return nil, fmt.Errorf("expiry or assignment of contract type '%s' is not supported for item '%v'", item.ContractType, item)
+		}
+		if item.Operation == ASSIGNMENT && item.Underlying == "" {
+			return nil, fmt.Errorf("assignment requires UNDERLYING for item '%v'", item)
+		}
+	default:
+		if item.ItemPrice < 0 {
+			return nil, fmt.Errorf("price '%v' is negative for item '%v'", item.ItemPrice, item)
+		}
+	}
+	return item, nil
+}
+
+func ProcessDerivatives(filePath string) (_ *TransactionLog, err error) {
+	log.Infof("%ss: processing input file '%s'", DerivativeItemType, filePath)
+
+	f, err := excel.OpenFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		// Close the spreadsheet
+		if err := f.Close(); err != nil {
+			log.Errorf("cannot close file '%s' due to: %v", filePath, err)
+		}
+	}()
+
+	transactions := TransactionLog{}
+
+	log.Infof("%ss: Ingesting Trades", DerivativeItemType)
+	if transactions.Derivatives, err = processSheet(f, "TRADE", derivativeTradeTblLegend, newDerivativeTradeItem); err != nil {
+		log.Errorf("%ss: %v", DerivativeItemType, err)
+	}
+	log.Infof("%ss: Ingested Trades (count: %d)", DerivativeItemType, len(transactions.Derivatives))
+
+	return &transactions, nil
+}
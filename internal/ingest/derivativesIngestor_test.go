@@ -0,0 +1,37 @@
+package ingest
+
+import (
+	"testing"
+)
+
+func TestNewDerivativeTradeItem(t *testing.T) {
+	tests := []struct {
+		name           string
+		row            []string
+		wantErr        bool
+		wantOperation  TransactionType
+		wantBankAmount float64
+	}{
+		// 2 calls for 3.5 with multiplier 100 and fee 2 (date 1.6.2025)
+		{"buy option", []string{"AAA C100", "call", "45809", "BUY", "2", "3.5", "100", "2", "broker", "CZK"}, false, BUY, 702},
+		{"sell future", []string{"FUT", "FUTURE", "45809", "SELL", "1", "10", "50", "1", "broker", "CZK"}, false, SELL, 499},
+		{"exercise option", []string{"AAA C100", "CALL", "45809", "EXERCISE", "1", "", "100", "", "broker", "CZK", "AAA"}, false, ASSIGNMENT, 0},
+		{"assignment without underlying", []string{"AAA P90", "PUT", "45809", "ASSIGNMENT", "1", "", "100", "", "broker", "CZK"}, true, 0, 0},
+		{"expiry of CFD", []string{"CFD1", "CFD", "45809", "EXPIRY", "1", "", "1", "", "broker", "CZK"}, true, 0, 0},
+		{"unknown type", []string{"X", "SWAP", "45809", "BUY", "1", "1", "1", "0", "broker", "CZK"}, true, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newDerivativeTradeItem(tt.row)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newDerivativeTradeItem() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Operation != tt.wantOperation || got.BankAmount != tt.wantBankAmount {
+				t.Errorf("newDerivativeTradeItem() = %+v, want operation %v and bank amount %v", got, tt.wantOperation, tt.wantBankAmount)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package ingest
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/marty-cz/czech-tax-calculator/internal/util"
 	log "github.com/sirupsen/logrus"
@@ -22,6 +23,13 @@ var (
 		"LOCATION": 2,
 		"CURRENCY": 3,
 	}
+	TRANSFER_TBL_LEGEND = map[string]int{
+		"NAME":        0,
+		"DATE":        1,
+		"QUANTITY":    2,
+		"FROM BROKER": 3,
+		"TO BROKER":   4,
+	}
 )
 
 func newAdditionalIncomeItem(row []string) (_ *TransactionLogItem, err error) {
@@ -79,6 +87,47 @@ func newAdditionalFeeItem(row []string) (_ *TransactionLogItem, err error) {
 	return &item, nil
 }
 
+func newTransferItem(row []string) (_ *TransactionLogItem, err error) {
+	item := TransactionLogItem{
+		Name:         row[TRANSFER_TBL_LEGEND["NAME"]],
+		Broker:       strings.TrimSpace(row[TRANSFER_TBL_LEGEND["FROM BROKER"]]),
+		TargetBroker: strings.TrimSpace(row[TRANSFER_TBL_LEGEND["TO BROKER"]]),
+		Operation:    TRANSFER,
+	}
+
+	if rawDate, err := strconv.ParseFloat(row[TRANSFER_TBL_LEGEND["DATE"]], 64); err != nil {
+		return nil, fmt.Errorf("raw date is not a number: %v", err)
+	} else if item.Date, err = excel.ExcelDateToTime(rawDate, false); err != nil {
+		return nil, fmt.Errorf("date has invalid format: %v", err)
+	}
+	if item.Quantity, err = strconv.ParseFloat(row[TRANSFER_TBL_LEGEND["QUANTITY"]], 64); err != nil {
+		return nil, fmt.Errorf("quantity is not a number: %v", err)
+	} else if item.Quantity <= 0 {
+		return nil, fmt.Errorf("quantity '%v' is not positive", item.Quantity)
+	}
+	if item.Broker == "" || item.TargetBroker == "" || strings.EqualFold(item.Broker, item.TargetBroker) {
+		return nil, fmt.Errorf("transfer requires two different brokers for item '%v'", &item)
+	}
+	return &item, nil
+}
+
+// returns value of a cell which may be empty (trailing empty cells are not part of a row)
+func getOptionalCell(row []string, index int) string {
+	if index < len(row) {
+		return strings.TrimSpace(row[index])
+	}
+	return ""
+}
+
+// processes a sheet which may be missing in the input file (then nothing is ingested)
+func processOptionalSheet(excelFile *excel.File, sheetName string, legend map[string]int, newItemFunction newTransactionItem) (transactions TransactionLogItems, err error) {
+	if index, err := excelFile.GetSheetIndex(sheetName); err != nil || index < 0 {
+		log.Debugf("sheet '%s' is not present, skipping", sheetName)
+		return make(TransactionLogItems, 0), nil
+	}
+	return processSheet(excelFile, sheetName, legend, newItemFunction)
+}
+
 func processSheet(excelFile *excel.File, sheetName string, legend map[string]int, newItemFunction newTransactionItem) (transactions TransactionLogItems, err error) {
 	rows, err := excelFile.GetRows(sheetName, excel.Options{RawCellValue: true})
 	if err != nil {
@@ -102,6 +151,8 @@ func processSheet(excelFile *excel.File, sheetName string, legend map[string]int
 		if err != nil {
 			return nil, fmt.Errorf("sheet '%s' (row '%d'): %v", sheetName, excelRowNo, err)
 		}
+		item.SheetName = sheetName
+		item.SheetRow = excelRowNo
 
 		transactions = append(transactions, item)
 		log.Debugf("ingested from '%s' (row '%d'): %+v", sheetName, excelRowNo, item)
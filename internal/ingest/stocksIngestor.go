@@ -5,14 +5,17 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/marty-cz/czech-tax-calculator/internal/rules"
 	"github.com/marty-cz/czech-tax-calculator/internal/util"
 	log "github.com/sirupsen/logrus"
 	excel "github.com/xuri/excelize/v2"
 )
 
-const MaxAllowedTax float64 = 0.15
 const StockItemType string = "stock"
 
+// tolerated difference between explicit and derived withheld tax of a dividend
+const paidTaxTolerance float64 = 0.01
+
 var (
 	stockBuyTblLegend = map[string]int{
 		"STOCK":       0,
@@ -46,6 +49,68 @@ var (
 		"CURRENCY": 6,
 		"COUNTRY":  7,
 	}
+	stockBondBuyTblLegend = map[string]int{
+		"BOND":             0,
+		"DATE":             1,
+		"BOND PRICE":       2,
+		"PAID":             3,
+		"FEE":              4,
+		"AMOUNT":           5,
+		"QUANTITY":         6,
+		"BROKER":           7,
+		"CURRENCY":         8,
+		"ACCRUED INTEREST": 9,
+		"COUNTRY":          10,
+	}
+	stockBondSellTblLegend = map[string]int{
+		"BOND":             0,
+		"DATE":             1,
+		"BOND PRICE":       2,
+		"RECEIVED":         3,
+		"FEE":              4,
+		"AMOUNT":           5,
+		"QUANTITY":         6,
+		"BROKER":           7,
+		"CURRENCY":         8,
+		"ACCRUED INTEREST": 9,
+		"COUNTRY":          10,
+	}
+	stockInterestTblLegend = map[string]int{
+		"NAME":     0,
+		"DATE":     1,
+		"RECEIVED": 2,
+		"AMOUNT":   3,
+		"PAID TAX": 4,
+		"BROKER":   5,
+		"CURRENCY": 6,
+		"COUNTRY":  7,
+	}
+	stockTaxRefundTblLegend = map[string]int{
+		"STOCK":         0,
+		"DATE":          1,
+		"DIVIDEND DATE": 2,
+		"AMOUNT":        3,
+		"BROKER":        4,
+		"CURRENCY":      5,
+		"COUNTRY":       6,
+	}
+	stockCorporateActionTblLegend = map[string]int{
+		"TYPE":       0,
+		"DATE":       1,
+		"STOCK":      2,
+		"RATIO":      3,
+		"NEW STOCK":  4,
+		"CASH":       5,
+		"CURRENCY":   6,
+		"COST SHARE": 7,
+	}
+	stockCorporateActionTypes = map[string]TransactionType{
+		"SPLIT":         SPLIT,
+		"REVERSE SPLIT": REVERSE_SPLIT,
+		"SYMBOL CHANGE": SYMBOL_CHANGE,
+		"MERGER":        MERGER,
+		"SPIN-OFF":      SPIN_OFF,
+	}
 )
 
 func newStockBuyItem(row []string) (_ *TransactionLogItem, err error) {
@@ -128,6 +193,120 @@ func newStockSellItem(row []string) (_ *TransactionLogItem, err error) {
 	return validateStockSellItem(&item)
 }
 
+// PAID includes the clean price (AMOUNT), fee and accrued interest - only the clean price and fee are purchase price,
+// the paid accrued interest lowers the interest income
+func newStockBondBuyItem(row []string) (_ *TransactionLogItem, err error) {
+	item, err := newBondItem(row, stockBondBuyTblLegend, "PAID", BUY)
+	if err != nil {
+		return nil, err
+	}
+	item.BankAmount -= item.AccruedInterest
+	if item.AccruedInterest > 0 {
+		item.RelatedItem = newAccruedInterestItem(item, -item.AccruedInterest)
+	}
+	return validateStockBuyItem(item)
+}
+
+// RECEIVED includes the clean price (AMOUNT) without fee and accrued interest - the accrued interest is an interest income
+func newStockBondSellItem(row []string) (_ *TransactionLogItem, err error) {
+	item, err := newBondItem(row, stockBondSellTblLegend, "RECEIVED", SELL)
+	if err != nil {
+		return nil, err
+	}
+	item.BankAmount -= item.AccruedInterest
+	if item.AccruedInterest > 0 {
+		item.RelatedItem = newAccruedInterestItem(item, item.AccruedInterest)
+	}
+	return validateStockSellItem(item)
+}
+
+// interest of accrued interest of a bond trade (received by sell, negative when paid by buy)
+func newAccruedInterestItem(item *TransactionLogItem, amount float64) *TransactionLogItem {
+	return &TransactionLogItem{
+		Name:               item.Name,
+		Date:               item.Date,
+		BankAmount:         amount,
+		OriginalBankAmount: amount,
+		BrokerAmount:       amount,
+		Broker:             item.Broker,
+		Currency:           item.Currency,
+		DayExchangeRate:    item.DayExchangeRate,
+		YearExchangeRate:   item.YearExchangeRate,
+		Operation:          INTEREST,
+		Country:            item.Country,
+		RelatedItem:        item,
+	}
+}
+
+func newBondItem(row []string, legend map[string]int, bankAmountColumn string, operation TransactionType) (_ *TransactionLogItem, err error) {
+	item := TransactionLogItem{
+		Name:      row[legend["BOND"]],
+		Broker:    row[legend["BROKER"]],
+		Operation: operation,
+	}
+
+	if rawDate, err := strconv.ParseFloat(row[legend["DATE"]], 64); err != nil {
+		return nil, fmt.Errorf("raw date is not a number: %v", err)
+	} else if item.Date, err = excel.ExcelDateToTime(rawDate, false); err != nil {
+		return nil, fmt.Errorf("date has invalid format: %v", err)
+	}
+	if item.ItemPrice, err = strconv.ParseFloat(row[legend["BOND PRICE"]], 64); err != nil {
+		return nil, fmt.Errorf("bond price is not a number: %v", err)
+	}
+	if item.BankAmount, err = strconv.ParseFloat(row[legend[bankAmountColumn]], 64); err != nil {
+		return nil, fmt.Errorf("%s is not a number: %v", strings.ToLower(bankAmountColumn), err)
+	}
+	item.OriginalBankAmount = item.BankAmount
+	if item.BrokerAmount, err = strconv.ParseFloat(row[legend["AMOUNT"]], 64); err != nil {
+		return nil, fmt.Errorf("amount is not a number: %v", err)
+	}
+	if item.Fee, err = strconv.ParseFloat(row[legend["FEE"]], 64); err != nil {
+		return nil, fmt.Errorf("fee is not a number: %v", err)
+	}
+	if item.Quantity, err = strconv.ParseFloat(row[legend["QUANTITY"]], 64); err != nil {
+		return nil, fmt.Errorf("quantity is not a number: %v", err)
+	}
+	if item.Currency, err = util.GetCurrencyByName(row[legend["CURRENCY"]]); err != nil {
+		return nil, fmt.Errorf("currency format problem: %v", err)
+	}
+	if accruedInterest := getOptionalCell(row, legend["ACCRUED INTEREST"]); accruedInterest != "" {
+		if item.AccruedInterest, err = strconv.ParseFloat(accruedInterest, 64); err != nil {
+			return nil, fmt.Errorf("accrued interest is not a number: %v", err)
+		} else if item.AccruedInterest < 0 {
+			return nil, fmt.Errorf("accrued interest '%v' is negative", item.AccruedInterest)
+		}
+	}
+	item.Country = rules.NormalizeCountry(getOptionalCell(row, legend["COUNTRY"]))
+	if item.AccruedInterest > 0 && item.Country == "" {
+		return nil, fmt.Errorf("cannot get country of accrued interest")
+	}
+	if item.DayExchangeRate, err = util.GetCzkExchangeRateInDay(item.Date, *item.Currency); err != nil {
+		return nil, fmt.Errorf("cannot get day exchange rate for %v from %v: %v", item.Currency, item.Date, err)
+	}
+	if item.YearExchangeRate, err = util.GetCzkExchangeRateInYear(item.Date, *item.Currency); err != nil {
+		return nil, fmt.Errorf("cannot get year exchange rate for %v from %v: %v", item.Currency, item.Date, err)
+	}
+	return &item, nil
+}
+
+// returns interests of accrued interest received by sold bonds and paid (negative) by bought bonds
+func getAccruedInterests(bondTrades TransactionLogItems) (interests TransactionLogItems) {
+	for _, item := range bondTrades {
+		if item.RelatedItem == nil {
+			continue
+		}
+		interest := item.RelatedItem
+		interest.SheetName = item.SheetName
+		interest.SheetRow = item.SheetRow
+		if interest.BrokerAmount < 0 {
+			log.Infof("accrued interest %v %s paid by purchase of '%s' at %s (sheet '%s' row '%d') lowers interest income",
+				-interest.BrokerAmount, interest.Currency.Name, interest.Name, interest.Date.Format("02.01.2006"), interest.SheetName, interest.SheetRow)
+		}
+		interests = append(interests, interest)
+	}
+	return
+}
+
 func newStockDividendItem(row []string) (_ *TransactionLogItem, err error) {
 	item := TransactionLogItem{
 		Name:      row[stockDividendTblLegend["STOCK"]],
@@ -147,6 +326,14 @@ func newStockDividendItem(row []string) (_ *TransactionLogItem, err error) {
 	if item.BrokerAmount, err = strconv.ParseFloat(row[stockDividendTblLegend["AMOUNT"]], 64); err != nil {
 		return nil, fmt.Errorf("amount is not a number: %v", err)
 	}
+	// PAID TAX is a rate of withheld tax from the amount (e.g. 0.15)
+	if paidTaxRate, err := strconv.ParseFloat(row[stockDividendTblLegend["PAID TAX"]], 64); err != nil {
+		return nil, fmt.Errorf("paid tax is not a number: %v", err)
+	} else if paidTaxRate < 0 || paidTaxRate > 1 {
+		return nil, fmt.Errorf("paid tax '%v' is not a rate between 0 and 1", paidTaxRate)
+	} else {
+		item.PaidTax = item.BrokerAmount * paidTaxRate
+	}
 	if item.Currency, err = util.GetCurrencyByName(row[stockDividendTblLegend["CURRENCY"]]); err != nil {
 		return nil, fmt.Errorf("currency format problem: %v", err)
 	}
@@ -156,13 +343,227 @@ func newStockDividendItem(row []string) (_ *TransactionLogItem, err error) {
 	if item.YearExchangeRate, err = util.GetCzkExchangeRateInYear(item.Date, *item.Currency); err != nil {
 		return nil, fmt.Errorf("cannot get year exchange rate for %v from %v: %v", item.Currency, item.Date, err)
 	}
-	item.Country = strings.ToUpper(row[stockDividendTblLegend["COUNTRY"]])
+	item.Country = rules.NormalizeCountry(row[stockDividendTblLegend["COUNTRY"]])
 	if item.Country == "" {
 		return nil, fmt.Errorf("cannot get country")
 	}
 	return validateDividendItem(&item)
 }
 
+func newStockInterestItem(row []string) (_ *TransactionLogItem, err error) {
+	item := TransactionLogItem{
+		Name:      row[stockInterestTblLegend["NAME"]],
+		Broker:    row[stockInterestTblLegend["BROKER"]],
+		Operation: INTEREST,
+	}
+
+	if rawDate, err := strconv.ParseFloat(row[stockInterestTblLegend["DATE"]], 64); err != nil {
+		return nil, fmt.Errorf("raw date is not a number: %v", err)
+	} else if item.Date, err = excel.ExcelDateToTime(rawDate, false); err != nil {
+		return nil, fmt.Errorf("date has invalid format: %v", err)
+	}
+	if item.BankAmount, err = strconv.ParseFloat(row[stockInterestTblLegend["RECEIVED"]], 64); err != nil {
+		return nil, fmt.Errorf("received is not a number: %v", err)
+	}
+	item.OriginalBankAmount = item.BankAmount
+	if item.BrokerAmount, err = strconv.ParseFloat(row[stockInterestTblLegend["AMOUNT"]], 64); err != nil {
+		return nil, fmt.Errorf("amount is not a number: %v", err)
+	}
+	// PAID TAX is a rate of withheld tax from the amount (e.g. 0.15)
+	if paidTaxRate, err := strconv.ParseFloat(row[stockInterestTblLegend["PAID TAX"]], 64); err != nil {
+		return nil, fmt.Errorf("paid tax is not a number: %v", err)
+	} else if paidTaxRate < 0 || paidTaxRate > 1 {
+		return nil, fmt.Errorf("paid tax '%v' is not a rate between 0 and 1", paidTaxRate)
+	} else {
+		item.PaidTax = item.BrokerAmount * paidTaxRate
+	}
+	if item.Currency, err = util.GetCurrencyByName(row[stockInterestTblLegend["CURRENCY"]]); err != nil {
+		return nil, fmt.Errorf("currency format problem: %v", err)
+	}
+	if item.DayExchangeRate, err = util.GetCzkExchangeRateInDay(item.Date, *item.Currency); err != nil {
+		return nil, fmt.Errorf("cannot get exchange rate for %v from %v: %v", item.Currency, item.Date, err)
+	}
+	if item.YearExchangeRate, err = util.GetCzkExchangeRateInYear(item.Date, *item.Currency); err != nil {
+		return nil, fmt.Errorf("cannot get year exchange rate for %v from %v: %v", item.Currency, item.Date, err)
+	}
+	item.Country = rules.NormalizeCountry(row[stockInterestTblLegend["COUNTRY"]])
+	if item.Country == "" {
+		return nil, fmt.Errorf("cannot get country")
+	}
+	return validateInterestItem(&item)
+}
+
+func newStockTaxRefundItem(row []string) (_ *TransactionLogItem, err error) {
+	item := TransactionLogItem{
+		Name:      row[stockTaxRefundTblLegend["STOCK"]],
+		Broker:    row[stockTaxRefundTblLegend["BROKER"]],
+		Operation: TAX_REFUND,
+	}
+
+	if rawDate, err := strconv.ParseFloat(row[stockTaxRefundTblLegend["DATE"]], 64); err != nil {
+		return nil, fmt.Errorf("raw date is not a number: %v", err)
+	} else if item.Date, err = excel.ExcelDateToTime(rawDate, false); err != nil {
+		return nil, fmt.Errorf("date has invalid format: %v", err)
+	}
+	if rawDate, err := strconv.ParseFloat(row[stockTaxRefundTblLegend["DIVIDEND DATE"]], 64); err != nil {
+		return nil, fmt.Errorf("raw dividend date is not a number: %v", err)
+	} else if item.RelatedDate, err = excel.ExcelDateToTime(rawDate, false); err != nil {
+		return nil, fmt.Errorf("dividend date has invalid format: %v", err)
+	}
+	if item.BrokerAmount, err = strconv.ParseFloat(row[stockTaxRefundTblLegend["AMOUNT"]], 64); err != nil {
+		return nil, fmt.Errorf("amount is not a number: %v", err)
+	} else if item.BrokerAmount <= 0 {
+		return nil, fmt.Errorf("amount '%v' is not positive", item.BrokerAmount)
+	}
+	item.BankAmount = item.BrokerAmount
+	item.OriginalBankAmount = item.BankAmount
+	if item.Currency, err = util.GetCurrencyByName(row[stockTaxRefundTblLegend["CURRENCY"]]); err != nil {
+		return nil, fmt.Errorf("currency format problem: %v", err)
+	}
+	item.Country = rules.NormalizeCountry(row[stockTaxRefundTblLegend["COUNTRY"]])
+	if item.Country == "" {
+		return nil, fmt.Errorf("cannot get country")
+	}
+	return &item, nil
+}
+
+// links tax refunds to their paid dividends (refund without a matching dividend is skipped)
+func linkTaxRefunds(refunds TransactionLogItems, dividends TransactionLogItems) (linked TransactionLogItems) {
+	linked = make(TransactionLogItems, 0, len(refunds))
+	for _, refund := range refunds {
+		for _, dividend := range dividends {
+			if strings.EqualFold(dividend.Name, refund.Name) && strings.EqualFold(dividend.Broker, refund.Broker) &&
+				dividend.Country == refund.Country && util.IsSameDay(dividend.Date, refund.RelatedDate) {
+				refund.RelatedItem = dividend
+				break
+			}
+		}
+		if refund.RelatedItem == nil {
+			log.Errorf("%ss: sheet '%s' row %d: no dividend of '%s' (broker '%s', country '%s') paid on %s - tax refund skipped",
+				StockItemType, refund.SheetName, refund.SheetRow, refund.Name, refund.Broker, refund.Country, refund.RelatedDate.Format("02.01.2006"))
+			continue
+		}
+		if refund.RelatedItem.Currency != refund.Currency {
+			log.Errorf("%ss: sheet '%s' row %d: tax refund currency '%v' differs from dividend currency '%v' - tax refund skipped",
+				StockItemType, refund.SheetName, refund.SheetRow, refund.Currency, refund.RelatedItem.Currency)
+			refund.RelatedItem = nil
+			continue
+		}
+		if refund.Date.Year() != refund.RelatedItem.Date.Year() {
+			log.Warnf("%ss: tax refund of '%s' received in %d is netted in dividend year %d (supplementary tax return may be needed)",
+				StockItemType, refund.Name, refund.Date.Year(), refund.RelatedItem.Date.Year())
+		}
+		linked = append(linked, refund)
+	}
+	return
+}
+
+func newStockCorporateActionItem(row []string) (_ *TransactionLogItem, err error) {
+	item := TransactionLogItem{
+		Name:      row[stockCorporateActionTblLegend["STOCK"]],
+		NewName:   getOptionalCell(row, stockCorporateActionTblLegend["NEW STOCK"]),
+		Ratio:     1,
+		CostShare: 1,
+	}
+
+	actionType := strings.ToUpper(strings.TrimSpace(row[stockCorporateActionTblLegend["TYPE"]]))
+	var exist bool
+	if item.Operation, exist = stockCorporateActionTypes[actionType]; !exist {
+		return nil, fmt.Errorf("unsupported corporate action type '%s'", actionType)
+	}
+	if rawDate, err := strconv.ParseFloat(row[stockCorporateActionTblLegend["DATE"]], 64); err != nil {
+		return nil, fmt.Errorf("raw date is not a number: %v", err)
+	} else if item.Date, err = excel.ExcelDateToTime(rawDate, false); err != nil {
+		return nil, fmt.Errorf("date has invalid format: %v", err)
+	}
+	if rawRatio := getOptionalCell(row, stockCorporateActionTblLegend["RATIO"]); rawRatio != "" {
+		if item.Ratio, err = parseRatio(rawRatio); err != nil {
+			return nil, fmt.Errorf("ratio format problem: %v", err)
+		}
+	}
+	if rawCash := getOptionalCell(row, stockCorporateActionTblLegend["CASH"]); rawCash != "" {
+		if item.ItemPrice, err = strconv.ParseFloat(rawCash, 64); err != nil {
+			return nil, fmt.Errorf("cash is not a number: %v", err)
+		} else if item.ItemPrice < 0 {
+			return nil, fmt.Errorf("cash '%v' is negative", item.ItemPrice)
+		}
+	}
+	if rawCostShare := getOptionalCell(row, stockCorporateActionTblLegend["COST SHARE"]); rawCostShare != "" {
+		if item.CostShare, err = strconv.ParseFloat(rawCostShare, 64); err != nil {
+			return nil, fmt.Errorf("cost share is not a number: %v", err)
+		} else if item.CostShare < 0 || item.CostShare > 1 {
+			return nil, fmt.Errorf("cost share '%v' is not between 0 and 1", item.CostShare)
+		}
+	}
+	if item.ItemPrice > 0 {
+		if item.Currency, err = util.GetCurrencyByName(getOptionalCell(row, stockCorporateActionTblLegend["CURRENCY"])); err != nil {
+			return nil, fmt.Errorf("currency format problem: %v", err)
+		}
+		if item.DayExchangeRate, err = util.GetCzkExchangeRateInDay(item.Date, *item.Currency); err != nil {
+			return nil, fmt.Errorf("cannot get exchange rate for %v from %v: %v", item.Currency, item.Date, err)
+		}
+		if item.YearExchangeRate, err = util.GetCzkExchangeRateInYear(item.Date, *item.Currency); err != nil {
+			return nil, fmt.Errorf("cannot get year exchange rate for %v from %v: %v", item.Currency, item.Date, err)
+		}
+	}
+	return validateCorporateActionItem(&item)
+}
+
+func validateCorporateActionItem(item *TransactionLogItem) (_ *TransactionLogItem, err error) {
+	switch item.Operation {
+	case SPLIT:
+		if item.Ratio <= 1 {
+			return nil, fmt.Errorf("ratio '%v' of split is not greater than 1", item.Ratio)
+		}
+	case REVERSE_SPLIT:
+		if item.Ratio <= 0 || item.Ratio >= 1 {
+			return nil, fmt.Errorf("ratio '%v' of reverse split is not between 0 and 1", item.Ratio)
+		}
+	case SYMBOL_CHANGE:
+		if item.NewName == "" || item.Ratio <= 0 {
+			return nil, fmt.Errorf("symbol change requires new stock and positive ratio for item '%v'", item)
+		}
+	case MERGER:
+		if item.Ratio > 0 && item.NewName == "" {
+			return nil, fmt.Errorf("merger to shares requires new stock for item '%v'", item)
+		}
+		// purchase price not carried over to new shares belongs to received cash
+		if item.Ratio == 0 && item.CostShare > 0 && item.ItemPrice > 0 {
+			item.CostShare = 0
+		}
+		if (item.ItemPrice > 0) != (item.CostShare < 1) {
+			return nil, fmt.Errorf("merger with cash requires cost share (of new stock) less than 1 and vice versa for item '%v'", item)
+		}
+	case SPIN_OFF:
+		if item.NewName == "" || item.Ratio <= 0 || item.CostShare <= 0 || item.CostShare >= 1 {
+			return nil, fmt.Errorf("spin-off requires new stock, positive ratio and cost share (of new stock) between 0 and 1 for item '%v'", item)
+		}
+	}
+	return item, nil
+}
+
+// parses ratio of new to original items given as 'new:original' (e.g. '4:1') or a number
+func parseRatio(value string) (float64, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) > 2 {
+		return 0, fmt.Errorf("ratio '%s' is not in 'new:original' format", value)
+	}
+	ratio, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, fmt.Errorf("ratio '%s' is not a number: %v", value, err)
+	}
+	if len(parts) == 2 {
+		original, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || original <= 0 {
+			return 0, fmt.Errorf("ratio '%s' has invalid original count", value)
+		}
+		ratio /= original
+	}
+	if ratio < 0 {
+		return 0, fmt.Errorf("ratio '%s' is negative", value)
+	}
+	return ratio, nil
+}
 
 func validateStockBuyItem(item *TransactionLogItem) (_ *TransactionLogItem, err error) {
 	if !util.LeqWithTolerance(item.BrokerAmount, item.BankAmount, 0.0001) {
@@ -186,17 +587,72 @@ func validateStockSellItem(item *TransactionLogItem) (_ *TransactionLogItem, err
 
 
 func validateDividendItem(item *TransactionLogItem) (_ *TransactionLogItem, err error) {
-	if !util.LeqWithTolerance(item.BankAmount, item.BrokerAmount, 0.0001) {
-		return nil, fmt.Errorf("Bank amount (RECEIVED) is greater than Broker amount (AMOUNT) for item '%v'", item)
+	if err = reconcilePaidTax(item); err != nil {
+		return nil, err
+	}
+	// tax withheld above the treaty rate is not creditable (it may be reclaimed)
+	maxAllowedTax := rules.GetDividendTreatyRate(item.Country, item.Date.Year())
+	paidTaxRate := item.PaidTax / item.BrokerAmount
+	if !util.LeqWithTolerance(paidTaxRate, maxAllowedTax, 0.01) {
+		item.PaidTax = item.BrokerAmount * maxAllowedTax
+		item.BankAmount = item.BrokerAmount - item.PaidTax - item.Fee
+		log.Warnf("Paid tax '%f' exceeds max allowed tax '%v' of country '%s' for item '%v' - adjusting Bank Amount to %f", paidTaxRate, maxAllowedTax, item.Country, item, item.BankAmount)
+	}
+	return item, nil
+}
+
+// interest has no treaty rate limitation - the whole withheld tax is kept
+func validateInterestItem(item *TransactionLogItem) (_ *TransactionLogItem, err error) {
+	if err = reconcilePaidTax(item); err != nil {
+		return nil, err
 	}
-	paidTax := 1 - (item.BankAmount / item.BrokerAmount)
-	if !util.LeqWithTolerance(paidTax, MaxAllowedTax, 0.01) {
-		item.BankAmount = item.BrokerAmount * (1 - MaxAllowedTax)
-		log.Warnf("Paid tax '%f' exceeds max allowed tax '%v' for item '%v' - adjusting Bank Amount to %f", paidTax, MaxAllowedTax, item, item.BankAmount)
-	}	
 	return item, nil
 }
 
+// reconciles explicit withheld tax with the difference between AMOUNT and RECEIVED
+func reconcilePaidTax(item *TransactionLogItem) error {
+	if !util.LeqWithTolerance(item.BankAmount, item.BrokerAmount, 0.0001) {
+		return fmt.Errorf("Bank amount (RECEIVED) is greater than Broker amount (AMOUNT) for item '%v'", item)
+	}
+	// whatever is withheld above the explicit tax is a broker fee
+	derivedPaidTax := item.BrokerAmount - item.BankAmount
+	item.ExplicitPaidTax = item.PaidTax
+	item.PaidTaxMismatch = derivedPaidTax - item.PaidTax
+	if item.PaidTaxMismatch > paidTaxTolerance {
+		item.Fee = item.PaidTaxMismatch
+	} else if item.PaidTaxMismatch < -paidTaxTolerance {
+		// more money received than explicit tax allows - trust the amounts
+		item.PaidTax = derivedPaidTax
+	}
+	item.OriginalPaidTax = item.PaidTax
+	return nil
+}
+
+// logs dividends (or interests) whose explicit PAID TAX does not match RECEIVED and AMOUNT
+func reportPaidTaxMismatches(dividends TransactionLogItems) (count int) {
+	for _, item := range dividends {
+		if !item.HasPaidTaxMismatch() {
+			continue
+		}
+		count++
+		log.Warnf("%ss: sheet '%s' row %d: '%s' withheld %f (PAID TAX %f) - %s", StockItemType, item.SheetName, item.SheetRow, item.Name,
+			item.ExplicitPaidTax+item.PaidTaxMismatch, item.ExplicitPaidTax, item.GetPaidTaxMismatchResolution())
+	}
+	return
+}
+
+func (x *TransactionLogItem) HasPaidTaxMismatch() bool {
+	return !util.EqWithTolerance(x.PaidTaxMismatch, 0, paidTaxTolerance)
+}
+
+// how the mismatch of explicit and derived withheld tax is resolved by reconcilePaidTax
+func (x *TransactionLogItem) GetPaidTaxMismatchResolution() string {
+	if x.PaidTaxMismatch > 0 {
+		return "withheld more than PAID TAX - the rest counted as broker fee"
+	}
+	return "withheld less than PAID TAX - PAID TAX replaced by tax derived from amounts"
+}
+
 func ProcessStocks(filePath string) (_ *TransactionLog, err error) {
 	log.Infof("%ss: processing input file '%s'", StockItemType, filePath)
 
@@ -225,11 +681,56 @@ func ProcessStocks(filePath string) (_ *TransactionLog, err error) {
 	}
 	log.Infof("%ss: Ingested Sales (count: %d)", StockItemType, len(transactions.Sales))
 
+	log.Infof("%ss: Ingesting Bonds", StockItemType)
+	bondPurchases, err := processOptionalSheet(f, "BOND BUY", stockBondBuyTblLegend, newStockBondBuyItem)
+	if err != nil {
+		log.Errorf("%ss: %v", StockItemType, err)
+	}
+	bondSales, err := processOptionalSheet(f, "BOND SELL", stockBondSellTblLegend, newStockBondSellItem)
+	if err != nil {
+		log.Errorf("%ss: %v", StockItemType, err)
+	}
+	transactions.Purchases = append(transactions.Purchases, bondPurchases...)
+	transactions.Sales = append(transactions.Sales, bondSales...)
+	log.Infof("%ss: Ingested Bonds (purchases: %d, sales: %d)", StockItemType, len(bondPurchases), len(bondSales))
+
+	log.Infof("%ss: Ingesting Transfers", StockItemType)
+	if transactions.Transfers, err = processOptionalSheet(f, "TRANSFER", TRANSFER_TBL_LEGEND, newTransferItem); err != nil {
+		log.Errorf("%ss: %v", StockItemType, err)
+	}
+	log.Infof("%ss: Ingested Transfers (count: %d)", StockItemType, len(transactions.Transfers))
+
 	log.Infof("%ss: Ingesting Dividends", StockItemType)
 	if transactions.Dividends, err = processSheet(f, "DIVIDEND", stockDividendTblLegend, newStockDividendItem); err != nil {
 		log.Errorf("%ss: %v", StockItemType, err)
 	}
 	log.Infof("%ss: Ingested Dividends (count: %d)", StockItemType, len(transactions.Dividends))
+	if count := reportPaidTaxMismatches(transactions.Dividends); count > 0 {
+		log.Warnf("%ss: Paid tax mismatches in Dividends (count: %d)", StockItemType, count)
+	}
+
+	log.Infof("%ss: Ingesting Interests", StockItemType)
+	if transactions.Interests, err = processOptionalSheet(f, "INTEREST", stockInterestTblLegend, newStockInterestItem); err != nil {
+		log.Errorf("%ss: %v", StockItemType, err)
+	}
+	transactions.Interests = append(transactions.Interests, getAccruedInterests(append(bondPurchases, bondSales...))...)
+	log.Infof("%ss: Ingested Interests (count: %d)", StockItemType, len(transactions.Interests))
+	if count := reportPaidTaxMismatches(transactions.Interests); count > 0 {
+		log.Warnf("%ss: Paid tax mismatches in Interests (count: %d)", StockItemType, count)
+	}
+
+	log.Infof("%ss: Ingesting Tax Refunds", StockItemType)
+	if transactions.TaxRefunds, err = processOptionalSheet(f, "TAX REFUND", stockTaxRefundTblLegend, newStockTaxRefundItem); err != nil {
+		log.Errorf("%ss: %v", StockItemType, err)
+	}
+	transactions.TaxRefunds = linkTaxRefunds(transactions.TaxRefunds, transactions.Dividends)
+	log.Infof("%ss: Ingested Tax Refunds (count: %d)", StockItemType, len(transactions.TaxRefunds))
+
+	log.Infof("%ss: Ingesting Corporate Actions", StockItemType)
+	if transactions.CorporateActions, err = processOptionalSheet(f, "CORPORATE ACTIONS", stockCorporateActionTblLegend, newStockCorporateActionItem); err != nil {
+		log.Errorf("%ss: %v", StockItemType, err)
+	}
+	log.Infof("%ss: Ingested Corporate Actions (count: %d)", StockItemType, len(transactions.CorporateActions))
 
 	log.Infof("%ss: Ingesting Additional Incomes", StockItemType)
 	if transactions.AdditionalIncomes, err = processSheet(f, "ADDITIONAL INCOME", ADDITIONAL_INCOME_TBL_LEGEND, newAdditionalIncomeItem); err != nil {
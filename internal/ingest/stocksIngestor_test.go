@@ -0,0 +1,143 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marty-cz/czech-tax-calculator/internal/util"
+)
+
+func TestValidateDividendItem(t *testing.T) {
+	tests := []struct {
+		name            string
+		country         string
+		received        float64
+		paidTax         float64
+		wantPaidTax     float64
+		wantOriginalTax float64
+		wantFee         float64
+		wantBankAmount  float64
+	}{
+		{"matching tax", "USA", 85, 15, 15, 15, 0, 85},
+		{"within tolerance", "USA", 85.005, 15, 15, 15, 0, 85.005},
+		{"broker fee withheld", "USA", 84, 15, 15, 15, 1, 84},
+		{"explicit tax too high", "USA", 90, 15, 10, 10, 0, 90},
+		{"tax above treaty rate", "GERMANY", 73.875, 26.125, 15, 26.125, 0, 85},
+		{"tax above treaty rate with fee", "GERMANY", 72.875, 26.125, 15, 26.125, 1, 84},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &TransactionLogItem{
+				Name:               "ITEM",
+				Date:               time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC),
+				BrokerAmount:       100,
+				BankAmount:         tt.received,
+				OriginalBankAmount: tt.received,
+				PaidTax:            tt.paidTax,
+				Country:            tt.country,
+				Operation:          DIVIDEND,
+			}
+			got, err := validateDividendItem(item)
+			if err != nil {
+				t.Fatalf("validateDividendItem() error = %v", err)
+			}
+			if !util.EqWithTolerance(got.PaidTax, tt.wantPaidTax, 1e-9) {
+				t.Errorf("PaidTax = %v, want %v", got.PaidTax, tt.wantPaidTax)
+			}
+			if !util.EqWithTolerance(got.OriginalPaidTax, tt.wantOriginalTax, 1e-9) {
+				t.Errorf("OriginalPaidTax = %v, want %v", got.OriginalPaidTax, tt.wantOriginalTax)
+			}
+			if got.ExplicitPaidTax != tt.paidTax || got.HasPaidTaxMismatch() == util.EqWithTolerance(tt.received+tt.paidTax, 100, 0.01) {
+				t.Errorf("ExplicitPaidTax = %v, HasPaidTaxMismatch() = %v, want PAID TAX %v kept", got.ExplicitPaidTax, got.HasPaidTaxMismatch(), tt.paidTax)
+			}
+			if !util.EqWithTolerance(got.Fee, tt.wantFee, 1e-9) {
+				t.Errorf("Fee = %v, want %v", got.Fee, tt.wantFee)
+			}
+			if !util.EqWithTolerance(got.BankAmount, tt.wantBankAmount, 1e-9) {
+				t.Errorf("BankAmount = %v, want %v", got.BankAmount, tt.wantBankAmount)
+			}
+		})
+	}
+}
+
+func TestParseRatio(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{"4:1", 4, false},
+		{" 1 : 10 ", 0.1, false},
+		{"2.5", 2.5, false},
+		{"1:0", 0, true},
+		{"0", 0, false},
+		{"-1", 0, true},
+		{"1:2:3", 0, true},
+		{"abc", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseRatio(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRatio() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !util.EqWithTolerance(got, tt.want, 1e-9) {
+				t.Errorf("parseRatio() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewStockBondItems(t *testing.T) {
+	// 10 bonds bought for 10,000 CZK clean price with fee 50 CZK and accrued interest 120 CZK (date 1.6.2025)
+	buy, err := newStockBondBuyItem([]string{"CZGB", "45809", "1000", "10170", "50", "10000", "10", "broker", "CZK", "120", "CZ"})
+	if err != nil {
+		t.Fatalf("newStockBondBuyItem() error = %v", err)
+	}
+	if buy.BankAmount != 10_050 || buy.OriginalBankAmount != 10_170 || buy.AccruedInterest != 120 {
+		t.Errorf("buy = %+v, want purchase price 10050 without accrued interest 120", buy)
+	}
+
+	// sold for 11,000 CZK clean price with fee 50 CZK and accrued interest 200 CZK
+	sell, err := newStockBondSellItem([]string{"DEGB", "45809", "1100", "11150", "50", "11000", "10", "broker", "CZK", "200", "DE"})
+	if err != nil {
+		t.Fatalf("newStockBondSellItem() error = %v", err)
+	}
+	if sell.BankAmount != 10_950 || sell.BrokerAmount != 11_000 {
+		t.Errorf("sell = %+v, want revenue 11000 without accrued interest", sell)
+	}
+	// accrued interest paid by the buy lowers interest income
+	interests := getAccruedInterests(TransactionLogItems{sell, buy})
+	if len(interests) != 2 || interests[0].Operation != INTEREST || interests[0].BrokerAmount != 200 || interests[0].Country != "GERMANY" {
+		t.Errorf("getAccruedInterests() = %v, want interest 200 from GERMANY first", interests)
+	}
+	if len(interests) == 2 && (interests[1].Operation != INTEREST || interests[1].BrokerAmount != -120 || interests[1].Country != "CZECHIA" || interests[1].RelatedItem != buy) {
+		t.Errorf("getAccruedInterests() = %v, want interest -120 from CZECHIA of the buy", interests)
+	}
+
+	if _, err := newStockBondSellItem([]string{"DEGB", "45809", "1100", "11150", "50", "11000", "10", "broker", "CZK", "200"}); err == nil {
+		t.Errorf("newStockBondSellItem() without country of accrued interest, want error")
+	}
+	if _, err := newStockBondBuyItem([]string{"CZGB", "45809", "1000", "10170", "50", "10000", "10", "broker", "CZK", "120"}); err == nil {
+		t.Errorf("newStockBondBuyItem() without country of accrued interest, want error")
+	}
+}
+
+func TestLinkTaxRefunds(t *testing.T) {
+	// dividend dates keep time of day from the sheet, dividend date of the refund does not
+	dividend := &TransactionLogItem{Name: "AAA", Broker: "broker", Country: "GERMANY", Currency: util.CZK,
+		Date: time.Date(2022, 5, 1, 4, 19, 12, 0, time.UTC), Operation: DIVIDEND}
+	newRefund := func(dividendDate time.Time) *TransactionLogItem {
+		return &TransactionLogItem{Name: "aaa", Broker: "broker", Country: "GERMANY", Currency: util.CZK,
+			Date: time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC), RelatedDate: dividendDate, BrokerAmount: 10, Operation: TAX_REFUND}
+	}
+	sameDayRefund := newRefund(time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC))
+	otherDayRefund := newRefund(time.Date(2022, 5, 2, 0, 0, 0, 0, time.UTC))
+	linked := linkTaxRefunds(TransactionLogItems{sameDayRefund, otherDayRefund}, TransactionLogItems{dividend})
+	if len(linked) != 1 || linked[0] != sameDayRefund || sameDayRefund.RelatedItem != dividend {
+		t.Errorf("linkTaxRefunds() = %v, want the refund of the same day linked to the dividend", linked)
+	}
+	if otherDayRefund.RelatedItem != nil {
+		t.Errorf("linkTaxRefunds() linked refund of other day to %v", otherDayRefund.RelatedItem)
+	}
+}
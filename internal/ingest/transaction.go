@@ -11,8 +11,14 @@ type TransactionLog struct {
 	Purchases         TransactionLogItems
 	Sales             TransactionLogItems
 	Dividends         TransactionLogItems
+	Interests         TransactionLogItems
 	AdditionalIncomes TransactionLogItems
 	AdditionalFees    TransactionLogItems
+	TaxRefunds        TransactionLogItems
+	CorporateActions  TransactionLogItems
+	Rewards           TransactionLogItems
+	Transfers         TransactionLogItems
+	Derivatives       TransactionLogItems
 }
 
 type TransactionType int64
@@ -23,8 +29,33 @@ const (
 	DIVIDEND
 	ADDITIONAL_INCOME
 	ADDITIONAL_FEE
+	TAX_REFUND
+	SPLIT
+	REVERSE_SPLIT
+	SYMBOL_CHANGE
+	MERGER
+	SPIN_OFF
+	REWARD
+	TRANSFER
+	INTEREST
+	EXPIRY
+	ASSIGNMENT
 )
 
+// type of derivative contract
+type ContractType string
+
+const (
+	CALL   ContractType = "CALL"
+	PUT    ContractType = "PUT"
+	FUTURE ContractType = "FUTURE"
+	CFD    ContractType = "CFD"
+)
+
+func (x ContractType) IsOption() bool {
+	return x == CALL || x == PUT
+}
+
 type TransactionLogItem struct {
 	// Name of item
 	Name string
@@ -39,10 +70,22 @@ type TransactionLogItem struct {
 	// amount of money used to buy/sell actual item at broker
 	BrokerAmount float64
 	Fee          float64
+	// adjusted (due to tax limitation) tax withheld at source (dividends, interests)
+	PaidTax float64
+	// non-adjusted tax withheld at source (dividends, interests)
+	OriginalPaidTax float64
+	// withheld tax stated in PAID TAX column (dividends, interests)
+	ExplicitPaidTax float64
+	// difference between withheld tax derived from amounts and the explicit one (dividends, interests)
+	PaidTaxMismatch float64
+	// accrued interest (AÚV) paid/received on top of the clean price of a bond (not part of Bank amount)
+	AccruedInterest float64
 	// count of items (event fractions)
 	Quantity float64
 	// name of Broker who backed the operation
 	Broker string
+	// name of Broker who receives the items (transfers)
+	TargetBroker string
 	// Currency used to buy the item (USD, EUR, CZK, ...)
 	Currency *util.Currency
 	// Exchange rate to CZK in the day of a transaction
@@ -53,6 +96,27 @@ type TransactionLogItem struct {
 	Operation TransactionType
 	// origin/target country where item was received/buyed
 	Country string
+	// name of input sheet the item was ingested from
+	SheetName string
+	// row number (1-based) in the input sheet the item was ingested from
+	SheetRow int
+	// date of the related transaction (paid dividend of a tax refund)
+	RelatedDate time.Time
+	// related transaction (paid dividend of a tax refund, acquisition of a swap disposal or a reward,
+	// accrued interest of a bond sell)
+	RelatedItem *TransactionLogItem
+	// count of new items per one original item (corporate actions)
+	Ratio float64
+	// name of the new item (corporate actions)
+	NewName string
+	// part of the original purchase price carried over to the new item (corporate actions)
+	CostShare float64
+	// type of derivative contract (derivatives)
+	ContractType ContractType
+	// count of underlying items per one contract (derivatives)
+	Multiplier float64
+	// name of the underlying item delivered on option assignment (derivatives)
+	Underlying string
 }
 
 type TransactionLogItems []*TransactionLogItem
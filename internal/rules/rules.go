@@ -0,0 +1,144 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tax law thresholds and limits valid in a tax year (all amounts in CZK)
+type RuleSet struct {
+	// first tax year the rule set is valid for
+	ValidSince int
+	// tax year the rule set is used for
+	Year int
+	// basic personal income tax rate
+	TaxRate float64
+	// tax rate of the tax base above HigherTaxRateThreshold (0 = no progressive rate)
+	HigherTaxRate float64
+	// tax base above which the higher tax rate is applied (depends on average wage of the year)
+	HigherTaxRateThreshold float64
+	// holding period (in months) after which sold securities are exempted (time test)
+	SecuritiesTimeTestMonths int
+	// yearly revenue of not time tested securities up to which the revenue is exempted (0 = no exemption)
+	SecuritiesRevenueExemptionLimit float64
+	// holding period (in months) of the time test for securities acquired before LegacySecuritiesAcquiredBefore (0 = no legacy test)
+	LegacySecuritiesTimeTestMonths int
+	LegacySecuritiesAcquiredBefore time.Time
+	// holding period (in months) after which sold cryptos are exempted (0 = no time test)
+	CryptoTimeTestMonths int
+	// yearly revenue of not time tested cryptos up to which the revenue is exempted (0 = no exemption)
+	CryptoRevenueExemptionLimit float64
+	// yearly other income (§10) up to which there is no obligation to file tax return
+	OtherIncomeFilingLimit float64
+	// yearly exempted (e.g. time tested) revenue above which it needs to be reported to the tax office (0 = no obligation)
+	ExemptRevenueReportingLimit float64
+	// yearly exempted time tested revenue above which it is taxed (0 = no cap)
+	TimeTestedExemptionCap float64
+}
+
+func (x *RuleSet) String() string {
+	return fmt.Sprintf("validSince:%d year:%d taxRate:%v higherTaxRate:%v higherTaxRateThreshold:%v securities:(timeTestMonths:%d exemptionLimit:%v legacyTimeTestMonths:%d legacyAcquiredBefore:%v) crypto:(timeTestMonths:%d exemptionLimit:%v) otherIncomeFilingLimit:%v exemptRevenueReportingLimit:%v timeTestedExemptionCap:%v",
+		x.ValidSince, x.Year, x.TaxRate, x.HigherTaxRate, x.HigherTaxRateThreshold, x.SecuritiesTimeTestMonths, x.SecuritiesRevenueExemptionLimit, x.LegacySecuritiesTimeTestMonths, x.LegacySecuritiesAcquiredBefore.Format("02.01.2006"), x.CryptoTimeTestMonths, x.CryptoRevenueExemptionLimit,
+		x.OtherIncomeFilingLimit, x.ExemptRevenueReportingLimit, x.TimeTestedExemptionCap)
+}
+
+// securities acquired before this date keep 6 months time test (transitional provision of zákon č. 344/2013 Sb.)
+var legacySecuritiesAcquiredBefore = time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// rule sets ordered by validity, each is valid until the next one
+var ruleSets = []*RuleSet{
+	// solidarity tax increase (2013 - 2020) applies to employment and business income only, thus it is not modelled
+	{
+		ValidSince:               2011,
+		TaxRate:                  0.15,
+		SecuritiesTimeTestMonths: 6,
+		OtherIncomeFilingLimit:   6_000,
+	},
+	// § 4 odst. 1 písm. w) and x) ZDP - 3 years time test and 100,000 CZK exemption
+	{
+		ValidSince:                      2014,
+		TaxRate:                         0.15,
+		SecuritiesTimeTestMonths:        36,
+		SecuritiesRevenueExemptionLimit: 100_000,
+		LegacySecuritiesTimeTestMonths:  6,
+		LegacySecuritiesAcquiredBefore:  legacySecuritiesAcquiredBefore,
+		OtherIncomeFilingLimit:          6_000,
+	},
+	// § 38v ZDP - reporting of exempted revenue above 5,000,000 CZK
+	{
+		ValidSince:                      2015,
+		TaxRate:                         0.15,
+		SecuritiesTimeTestMonths:        36,
+		SecuritiesRevenueExemptionLimit: 100_000,
+		LegacySecuritiesTimeTestMonths:  6,
+		LegacySecuritiesAcquiredBefore:  legacySecuritiesAcquiredBefore,
+		OtherIncomeFilingLimit:          6_000,
+		ExemptRevenueReportingLimit:     5_000_000,
+	},
+	// 23 % tax rate of tax base above 48 (36 since 2024) times average wage
+	{
+		ValidSince:                      2021,
+		TaxRate:                         0.15,
+		HigherTaxRate:                   0.23,
+		SecuritiesTimeTestMonths:        36,
+		SecuritiesRevenueExemptionLimit: 100_000,
+		LegacySecuritiesTimeTestMonths:  6,
+		LegacySecuritiesAcquiredBefore:  legacySecuritiesAcquiredBefore,
+		OtherIncomeFilingLimit:          6_000,
+		ExemptRevenueReportingLimit:     5_000_000,
+	},
+	// time test and exemption of cryptos, cap of exempted time tested revenue
+	{
+		ValidSince:                      2025,
+		TaxRate:                         0.15,
+		HigherTaxRate:                   0.23,
+		SecuritiesTimeTestMonths:        36,
+		SecuritiesRevenueExemptionLimit: 100_000,
+		LegacySecuritiesTimeTestMonths:  6,
+		LegacySecuritiesAcquiredBefore:  legacySecuritiesAcquiredBefore,
+		CryptoTimeTestMonths:            36,
+		CryptoRevenueExemptionLimit:     100_000,
+		OtherIncomeFilingLimit:          6_000,
+		ExemptRevenueReportingLimit:     5_000_000,
+		TimeTestedExemptionCap:          40_000_000,
+	},
+}
+
+// tax base thresholds of the higher tax rate in years (48 or 36 times average wage)
+var higherTaxRateThresholds = map[int]float64{
+	2021: 1_701_168,
+	2022: 1_867_728,
+	2023: 1_935_552,
+	2024: 1_582_812,
+	2025: 1_676_052,
+	2026: 1_762_812,
+}
+
+// Returns rule set valid in the tax year. Years before the oldest rule set use the oldest one.
+func ForYear(year int) *RuleSet {
+	ret := *ruleSets[0]
+	for _, ruleSet := range ruleSets {
+		if ruleSet.ValidSince <= year {
+			ret = *ruleSet
+		}
+	}
+	ret.Year = year
+	if ret.HigherTaxRate > 0.0 {
+		ret.HigherTaxRateThreshold = getHigherTaxRateThreshold(year)
+	}
+	return &ret
+}
+
+// threshold of the year or of the latest known year
+func getHigherTaxRateThreshold(year int) float64 {
+	latestYear := 0
+	for thresholdYear := range higherTaxRateThresholds {
+		if thresholdYear == year {
+			return higherTaxRateThresholds[year]
+		}
+		if thresholdYear > latestYear {
+			latestYear = thresholdYear
+		}
+	}
+	return higherTaxRateThresholds[latestYear]
+}
@@ -0,0 +1,54 @@
+package rules
+
+import (
+	"testing"
+)
+
+func TestForYear(t *testing.T) {
+	tests := []struct {
+		name               string
+		year               int
+		wantValidSince     int
+		wantThreshold      float64
+		wantCryptoTimeTest int
+		wantTimeTestedCap  float64
+		wantReportingLimit float64
+	}{
+		{"Before oldest rule set", 2005, 2011, 0, 0, 0, 0},
+		{"Before 2014", 2013, 2011, 0, 0, 0, 0},
+		{"Since 2014 - no reporting of exempted revenue", 2014, 2014, 0, 0, 0, 0},
+		{"Since 2015", 2019, 2015, 0, 0, 0, 5_000_000},
+		{"Since 2021", 2023, 2021, 1_935_552, 0, 0, 5_000_000},
+		{"Since 2025", 2025, 2025, 1_676_052, 36, 40_000_000, 5_000_000},
+		{"Unknown threshold uses latest known", 2030, 2025, 1_762_812, 36, 40_000_000, 5_000_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ForYear(tt.year)
+			if got.Year != tt.year || got.ValidSince != tt.wantValidSince || got.HigherTaxRateThreshold != tt.wantThreshold ||
+				got.CryptoTimeTestMonths != tt.wantCryptoTimeTest || got.TimeTestedExemptionCap != tt.wantTimeTestedCap ||
+				got.ExemptRevenueReportingLimit != tt.wantReportingLimit {
+				t.Errorf("ForYear() = %v", got)
+			}
+		})
+	}
+}
+
+func TestGetDividendTreatyRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		country string
+		want    float64
+	}{
+		{"Listed country", "France", 0.10},
+		{"Country alias", "us", 0.15},
+		{"Not listed country", "Atlantis", DefaultDividendTreatyRate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetDividendTreatyRate(tt.country, 2023); got != tt.want {
+				t.Errorf("GetDividendTreatyRate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
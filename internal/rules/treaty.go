@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"strings"
+)
+
+// unified name of Czechia - source country of domestic income
+const DomesticCountry string = "CZECHIA"
+
+// max dividend withholding tax rate (creditable in Czechia) when a country has no treaty rate defined
+const DefaultDividendTreatyRate float64 = 0.15
+
+// dividend withholding tax rate of a double taxation treaty valid since a year
+type treatyRate struct {
+	ValidSince int
+	Rate       float64
+}
+
+// Dividend withholding tax rates (for portfolio investors) defined by double taxation treaties with Czechia.
+// Tax withheld above the rate is not creditable and may be reclaimed in the source country.
+// Rates of a country are ordered by validity, each is valid until the next one.
+var dividendTreatyRates = map[string][]treatyRate{
+	"USA":         {{ValidSince: 2011, Rate: 0.15}},
+	"GERMANY":     {{ValidSince: 2011, Rate: 0.15}},
+	"AUSTRIA":     {{ValidSince: 2011, Rate: 0.10}},
+	"FRANCE":      {{ValidSince: 2011, Rate: 0.10}},
+	"NETHERLANDS": {{ValidSince: 2011, Rate: 0.10}},
+	"SWITZERLAND": {{ValidSince: 2011, Rate: 0.15}},
+	"IRELAND":     {{ValidSince: 2011, Rate: 0.15}},
+	"CANADA":      {{ValidSince: 2011, Rate: 0.15}},
+	"UK":          {{ValidSince: 2011, Rate: 0.15}},
+	"CZECHIA":     {{ValidSince: 2011, Rate: 0.15}},
+}
+
+// alternative names of countries used in input files
+var countryAliases = map[string]string{
+	"US":              "USA",
+	"UNITED STATES":   "USA",
+	"DE":              "GERMANY",
+	"AT":              "AUSTRIA",
+	"FR":              "FRANCE",
+	"NL":              "NETHERLANDS",
+	"CH":              "SWITZERLAND",
+	"IE":              "IRELAND",
+	"CA":              "CANADA",
+	"GB":              "UK",
+	"GREAT BRITAIN":   "UK",
+	"UNITED KINGDOM":  "UK",
+	"CZ":              "CZECHIA",
+	"CZECH REPUBLIC":  "CZECHIA",
+	"ČESKO":           "CZECHIA",
+	"ČESKÁ REPUBLIKA": "CZECHIA",
+}
+
+// Returns unified (upper case) name of a country
+func NormalizeCountry(country string) string {
+	name := strings.ToUpper(strings.TrimSpace(country))
+	if alias, exist := countryAliases[name]; exist {
+		return alias
+	}
+	return name
+}
+
+// Returns max creditable dividend withholding tax rate of the country valid in the year
+func GetDividendTreatyRate(country string, year int) float64 {
+	rates, exist := dividendTreatyRates[NormalizeCountry(country)]
+	if !exist || len(rates) == 0 || year < rates[0].ValidSince {
+		return DefaultDividendTreatyRate
+	}
+	ret := rates[0].Rate
+	for _, rate := range rates {
+		if rate.ValidSince <= year {
+			ret = rate.Rate
+		}
+	}
+	return ret
+}
+
+// Returns true when the country is Czechia (income from domestic sources)
+func IsDomesticCountry(country string) bool {
+	return NormalizeCountry(country) == DomesticCountry
+}
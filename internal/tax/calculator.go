@@ -2,16 +2,31 @@ package tax
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/marty-cz/czech-tax-calculator/internal/ingest"
+	"github.com/marty-cz/czech-tax-calculator/internal/rules"
 	"github.com/marty-cz/czech-tax-calculator/internal/util"
 	log "github.com/sirupsen/logrus"
 )
 
 var DEFAULT_CURRENCY *util.Currency = util.CZK
 
-func Calculate(transactions *ingest.TransactionLog, currentTaxYearString string, allowThreeYearsTimeTest bool) (reports Reports, err error) {
+// remaining quantity smaller than this is treated as a floating point error
+const quantityTolerance float64 = 1e-9
+
+// A sell is covered by items held in the day of sell only. In case of allowOversell is false, the calculation fails
+// when a sell is not covered by them. Otherwise such sells are only reported. In case of allowShort is true,
+// the rest of such sell opens a short position which is covered by later buys.
+func Calculate(transactions *ingest.TransactionLog, currentTaxYearString string, rules RulesProvider, strategy CostBasisStrategy, allowOversell bool, allowShort bool, perBrokerPools bool) (reports Reports, err error) {
+	if strategy == nil {
+		strategy = FIFO
+	}
+	if rules == nil {
+		rules = StockRules
+	}
 	currentTaxYear, err := util.GetYearFromString(currentTaxYearString)
 	if err != nil {
 		return nil, err
@@ -26,25 +41,49 @@ func Calculate(transactions *ingest.TransactionLog, currentTaxYearString string,
 	if len(transactions.AdditionalIncomes) > 0 && transactions.AdditionalIncomes[0].Date.Year() < oldestSellTransactionYear {
 		oldestSellTransactionYear = transactions.AdditionalIncomes[0].Date.Year()
 	}
+	if len(transactions.Rewards) > 0 && transactions.Rewards[0].Date.Year() < oldestSellTransactionYear {
+		oldestSellTransactionYear = transactions.Rewards[0].Date.Year()
+	}
+	if len(transactions.Interests) > 0 && transactions.Interests[0].Date.Year() < oldestSellTransactionYear {
+		oldestSellTransactionYear = transactions.Interests[0].Date.Year()
+	}
 
 	itemsToSell := convertToItemsToSell(transactions.Purchases)
+	// corporate actions and transfers are applied in order of their dates
+	pendingActions := append(append(ingest.TransactionLogItems{}, transactions.CorporateActions...), transactions.Transfers...)
+	sort.Stable(ByDate(pendingActions))
+	// short positions not covered yet (allowShort only)
+	var shorts shortPositions
 
 	// go through tax years from oldest to latest
 	for year := oldestSellTransactionYear; year <= currentTaxYear; year++ {
-		inYearSellOperations, dateStart, dateEnd, err := getItemSales(transactions.Sales, itemsToSell, year, allowThreeYearsTimeTest)
+		yearRules := rules(year)
+		inYearSellOperations, dateStart, dateEnd, err := getItemSales(transactions.Sales, &itemsToSell, &pendingActions, &shorts, year, yearRules, strategy, allowOversell, allowShort, perBrokerPools)
 		if err != nil {
 			return nil, fmt.Errorf("calculation for year '%v' failed: %v", year, err)
 		}
 		inYearDividends := getTransactionsInYear(transactions.Dividends, dateStart, dateEnd)
+		inYearInterests := getTransactionsInYear(transactions.Interests, dateStart, dateEnd)
 		inYearAdditionalIncomes := getTransactionsInYear(transactions.AdditionalIncomes, dateStart, dateEnd)
 		inYearAdditionalFees := getTransactionsInYear(transactions.AdditionalFees, dateStart, dateEnd)
-		reports = append(reports, calculateReport(inYearSellOperations, inYearDividends, inYearAdditionalIncomes, inYearAdditionalFees, dateStart))
+		inYearTaxRefunds := getTaxRefundsOfDividendsInYear(transactions.TaxRefunds, dateStart, dateEnd)
+		inYearRewards := getTransactionsInYear(transactions.Rewards, dateStart, dateEnd)
+		report := calculateReport(inYearSellOperations, inYearDividends, inYearInterests, inYearTaxRefunds, inYearRewards, inYearAdditionalIncomes, inYearAdditionalFees, dateStart)
+		report.CostBasisStrategy = strategy
+		report.PerBrokerPools = perBrokerPools
+		report.Rules = yearRules
+		report.RevenueExemption = report.EvaluateRevenueExemption(yearRules.RevenueExemptionLimit)
+		reports = append(reports, report)
+	}
+	for _, short := range shorts {
+		log.Warnf("short position of '%s' opened at %s (sheet '%s' row '%d') is still open (quantity %v)",
+			short.sellItem.Name, short.sellItem.Date.Format("02.01.2006"), short.sellItem.SheetName, short.sellItem.SheetRow, short.openQuantity)
 	}
 
 	return
 }
 
-func getItemSales(sellTransactions ingest.TransactionLogItems, itemsToSell ItemsToSell, year int, allowThreeYearsTimeTest bool) (SellOperations, time.Time, time.Time, error) {
+func getItemSales(sellTransactions ingest.TransactionLogItems, itemsToSell *ItemsToSell, pendingActions *ingest.TransactionLogItems, shorts *shortPositions, year int, yearRules *YearRules, strategy CostBasisStrategy, allowOversell bool, allowShort bool, perBrokerPools bool) (SellOperations, time.Time, time.Time, error) {
 	layout := "02.01.2006 15:04:05"
 	dateStart, _ := time.Parse(layout, fmt.Sprintf("01.01.%d 00:00:00", year))
 	dateEnd, _ := time.Parse(layout, fmt.Sprintf("31.12.%d 23:59:59", year))
@@ -54,17 +93,60 @@ func getItemSales(sellTransactions ingest.TransactionLogItems, itemsToSell Items
 
 	log.Infof("sale transactions count for year '%d': %d", year, len(inYearSellOperations))
 
+	// sells of cash received from corporate actions and covered short positions
+	var actionSellOperations, actionSellOps SellOperations
+	soldOperations := make(SellOperations, 0, len(inYearSellOperations))
 	for _, sellOp := range inYearSellOperations {
-		availableBuyItems := getAvailableItemsToSell(itemsToSell, sellOp.SellItem)
+		*pendingActions, actionSellOps = applyCorporateActions(*pendingActions, itemsToSell, sellOp.SellItem.Date, yearRules)
+		actionSellOperations = append(actionSellOperations, actionSellOps...)
+		if allowShort {
+			// buys made since the short sale cover it first
+			*shorts, actionSellOps = coverShortPositions(*shorts, *itemsToSell, sellOp.SellItem.Date, yearRules, perBrokerPools)
+			actionSellOperations = append(actionSellOperations, actionSellOps...)
+		}
+		// the sell takes only items held in its day, later buys can not cover it
+		availableBuyItems := filterItemsToSell(getAvailableItemsToSell(*itemsToSell, sellOp.SellItem, perBrokerPools), func(itemToSell *ItemToSell) bool {
+			return !itemToSell.buyItem.Date.After(sellOp.SellItem.Date)
+		})
 		log.Debugf("sell '%s' available buy items: %v", sellOp.SellItem.Name, availableBuyItems)
 
-		calculateSellExpense(sellOp, availableBuyItems, allowThreeYearsTimeTest)
+		strategy.CalculateSellExpense(sellOp, availableBuyItems, yearRules)
 		log.Debugf("sell operation processed: '%+v'", sellOp)
+		if allowShort && sellOp.IsOversold() {
+			var short *shortPosition
+			sellOp, short = openShortPosition(sellOp)
+			*shorts = append(*shorts, short)
+			if sellOp == nil {
+				continue
+			}
+		}
+		soldOperations = append(soldOperations, sellOp)
+	}
+	inYearSellOperations = soldOperations
+	*pendingActions, actionSellOps = applyCorporateActions(*pendingActions, itemsToSell, dateEnd, yearRules)
+	actionSellOperations = append(actionSellOperations, actionSellOps...)
+	if allowShort {
+		*shorts, actionSellOps = coverShortPositions(*shorts, *itemsToSell, dateEnd, yearRules, perBrokerPools)
+		actionSellOperations = append(actionSellOperations, actionSellOps...)
+	}
+	if len(actionSellOperations) > 0 {
+		inYearSellOperations = append(inYearSellOperations, actionSellOperations...)
+		sort.SliceStable(inYearSellOperations, func(i, j int) bool {
+			return inYearSellOperations[i].SellItem.Date.Before(inYearSellOperations[j].SellItem.Date)
+		})
+	}
+
+	oversoldOperations := inYearSellOperations.GetOversold()
+	for _, sellOp := range oversoldOperations {
+		log.Warnf("oversold: %s", sellOp.OversoldDescription())
+	}
+	if len(oversoldOperations) > 0 && !allowOversell {
+		return nil, dateStart, dateEnd, fmt.Errorf("%d sell(s) not covered by available buys, first: %s", len(oversoldOperations), oversoldOperations[0].OversoldDescription())
 	}
 	return inYearSellOperations, dateStart, dateEnd, nil
 }
 
-func calculateReport(sellOps SellOperations, dividends ingest.TransactionLogItems, additionalIncomes ingest.TransactionLogItems, additionalFees ingest.TransactionLogItems, year time.Time) *Report {
+func calculateReport(sellOps SellOperations, dividends ingest.TransactionLogItems, interests ingest.TransactionLogItems, taxRefunds ingest.TransactionLogItems, rewards ingest.TransactionLogItems, additionalIncomes ingest.TransactionLogItems, additionalFees ingest.TransactionLogItems, year time.Time) *Report {
 	report := Report{
 		SellOperations:            sellOps,
 		Year:                      year,
@@ -72,7 +154,11 @@ func calculateReport(sellOps SellOperations, dividends ingest.TransactionLogItem
 		TotalItemRevenue:          newAccountingValue(0, 0, DEFAULT_CURRENCY),
 		TimeTestedItemRevenue:     newAccountingValue(0, 0, DEFAULT_CURRENCY),
 		DividendReports:           make(map[string]*BrokerDividendReports),
+		DomesticDividendReports:   make(BrokerDividendReports),
+		InterestReports:           make(map[string]*BrokerDividendReports),
+		DomesticInterestReports:   make(BrokerDividendReports),
 		AdditionalRevenue:         newEmptyValueAndFee(DEFAULT_CURRENCY),
+		RewardRevenue:             newAccountingValue(0, 0, DEFAULT_CURRENCY),
 		TimeTestedItemFifoExpense: newEmptyValueAndFee(DEFAULT_CURRENCY),
 		TotalItemFifoExpense:      newEmptyValueAndFee(DEFAULT_CURRENCY),
 	}
@@ -105,38 +191,20 @@ func calculateReport(sellOps SellOperations, dividends ingest.TransactionLogItem
 
 	// calculate report for received dividends
 	for _, dividend := range dividends {
-		brokerDivReports, exist := report.DividendReports[dividend.Country]
-		if !exist {
-			brokerDivReports = &BrokerDividendReports{}
-		}
-		divReport, exist := brokerDivReports.Get(dividend.Broker)
-		if !exist {
-			divReport = &DividendReport{
-				RawRevenue:         newEmptyValueAndFee(DEFAULT_CURRENCY),
-				PaidTax:            newAccountingValue(0, 0, DEFAULT_CURRENCY),
-				OriginalRawRevenue: newEmptyValueAndFee(dividend.Currency),
-				OriginalPaidTax:    newAccountingValue(0, 0, dividend.Currency),
-				Country:    dividend.Country,
-				Broker:     dividend.Broker,
-			}
-		}
-		divReport.RawRevenue.Value.Add(newAccountingValue(
-			dividend.BrokerAmount*dividend.DayExchangeRate,
-			dividend.BrokerAmount*dividend.YearExchangeRate, report.Currency))
-		divReport.RawRevenue.Fee.Add(newAccountingValue(
-			dividend.Fee*dividend.DayExchangeRate,
-			dividend.Fee*dividend.YearExchangeRate, report.Currency))
-		divReport.OriginalRawRevenue.Value.Add(newAccountingValue(dividend.BrokerAmount, dividend.BrokerAmount, dividend.Currency))
-		divReport.OriginalRawRevenue.Fee.Add(newAccountingValue(dividend.Fee, dividend.Fee, dividend.Currency))
-		paidTax := dividend.BrokerAmount - dividend.BankAmount
-		divReport.PaidTax.Add(newAccountingValue(
-			paidTax*dividend.DayExchangeRate,
-			paidTax*dividend.YearExchangeRate, report.Currency))
-		originalPaidTax := dividend.BrokerAmount - dividend.OriginalBankAmount
-		divReport.OriginalPaidTax.Add(newAccountingValue(originalPaidTax, originalPaidTax, dividend.Currency))
-
-		brokerDivReports.Set(divReport.Broker, divReport)
-		report.DividendReports[dividend.Country] = brokerDivReports
+		addToDividendReports(dividend, report.DividendReports, report.DomesticDividendReports, taxRefunds)
+	}
+	// calculate report for received interests (without tax refunds)
+	for _, interest := range interests {
+		addToDividendReports(interest, report.InterestReports, report.DomesticInterestReports, nil)
+	}
+	report.PaidTaxMismatches = filterTransactionLog(append(append(ingest.TransactionLogItems{}, dividends...), interests...), func(item *ingest.TransactionLogItem) bool {
+		return item.HasPaidTaxMismatch()
+	})
+	// calculate report for received rewards
+	for _, reward := range rewards {
+		report.RewardRevenue.Add(newAccountingValue(
+			reward.BrokerAmount*reward.DayExchangeRate,
+			reward.BrokerAmount*reward.YearExchangeRate, report.Currency))
 	}
 	// calculate report for received additional income
 	for _, additionalIncome := range additionalIncomes {
@@ -154,9 +222,64 @@ func calculateReport(sellOps SellOperations, dividends ingest.TransactionLogItem
 	return &report
 }
 
-func calculateSellExpense(sellOp *SellOperation, availableBuyItems ItemsToSell, allowThreeYearsTimeTest bool) {
+// Adds a received dividend (or interest) to the reports of its country and broker, domestic ones are added
+// to domestic reports as they are settled by final withholding tax
+func addToDividendReports(dividend *ingest.TransactionLogItem, reports map[string]*BrokerDividendReports, domestic BrokerDividendReports, taxRefunds ingest.TransactionLogItems) {
+	brokerDivReports := &domestic
+	if !rules.IsDomesticCountry(dividend.Country) {
+		countryDivReports, exist := reports[dividend.Country]
+		if !exist {
+			countryDivReports = &BrokerDividendReports{}
+			reports[dividend.Country] = countryDivReports
+		}
+		brokerDivReports = countryDivReports
+	}
+	divReport, exist := brokerDivReports.Get(dividend.Broker)
+	if !exist {
+		divReport = &DividendReport{
+			RawRevenue:            newEmptyValueAndFee(DEFAULT_CURRENCY),
+			PaidTax:               newAccountingValue(0, 0, DEFAULT_CURRENCY),
+			OriginalRawRevenue:    newEmptyValueAndFee(dividend.Currency),
+			OriginalPaidTax:       newAccountingValue(0, 0, dividend.Currency),
+			ExcessPaidTax:         newAccountingValue(0, 0, DEFAULT_CURRENCY),
+			OriginalExcessPaidTax: newAccountingValue(0, 0, dividend.Currency),
+			RefundedTax:           newAccountingValue(0, 0, DEFAULT_CURRENCY),
+			OriginalRefundedTax:   newAccountingValue(0, 0, dividend.Currency),
+			Country:               dividend.Country,
+			Broker:                dividend.Broker,
+		}
+	}
+	divReport.RawRevenue.Value.Add(newAccountingValue(
+		dividend.BrokerAmount*dividend.DayExchangeRate,
+		dividend.BrokerAmount*dividend.YearExchangeRate, DEFAULT_CURRENCY))
+	divReport.RawRevenue.Fee.Add(newAccountingValue(
+		dividend.Fee*dividend.DayExchangeRate,
+		dividend.Fee*dividend.YearExchangeRate, DEFAULT_CURRENCY))
+	divReport.OriginalRawRevenue.Value.Add(newAccountingValue(dividend.BrokerAmount, dividend.BrokerAmount, dividend.Currency))
+	divReport.OriginalRawRevenue.Fee.Add(newAccountingValue(dividend.Fee, dividend.Fee, dividend.Currency))
+	// refunded tax lowers the excess (not creditable) tax first
+	refundedTax := math.Min(getRefundedTax(taxRefunds, dividend), dividend.OriginalPaidTax)
+	refundedExcessTax := math.Min(refundedTax, dividend.OriginalPaidTax-dividend.PaidTax)
+	paidTax := dividend.PaidTax - (refundedTax - refundedExcessTax)
+	divReport.PaidTax.Add(newAccountingValue(
+		paidTax*dividend.DayExchangeRate,
+		paidTax*dividend.YearExchangeRate, DEFAULT_CURRENCY))
+	originalPaidTax := dividend.OriginalPaidTax - refundedTax
+	divReport.OriginalPaidTax.Add(newAccountingValue(originalPaidTax, originalPaidTax, dividend.Currency))
+	divReport.RefundedTax.Add(newAccountingValue(
+		refundedTax*dividend.DayExchangeRate,
+		refundedTax*dividend.YearExchangeRate, DEFAULT_CURRENCY))
+	divReport.OriginalRefundedTax.Add(newAccountingValue(refundedTax, refundedTax, dividend.Currency))
+	excessPaidTax := originalPaidTax - paidTax
+	divReport.ExcessPaidTax.Add(newAccountingValue(
+		excessPaidTax*dividend.DayExchangeRate,
+		excessPaidTax*dividend.YearExchangeRate, DEFAULT_CURRENCY))
+	divReport.OriginalExcessPaidTax.Add(newAccountingValue(excessPaidTax, excessPaidTax, dividend.Currency))
+
+	brokerDivReports.Set(divReport.Broker, divReport)
+}
 
-	timeTestDate := util.GetDateThreeYearsBefore(sellOp.SellItem.Date)
+func calculateSellExpense(sellOp *SellOperation, availableBuyItems ItemsToSell, yearRules *YearRules) {
 
 	quantityToBeSold := sellOp.SellItem.Quantity
 	for _, itemToSell := range availableBuyItems {
@@ -166,36 +289,25 @@ func calculateSellExpense(sellOp *SellOperation, availableBuyItems ItemsToSell,
 
 		soldItem := &SoldItem{
 			BuyItem: itemToSell.buyItem,
-			// 3 years time test
-			TimeTested: allowThreeYearsTimeTest && itemToSell.buyItem.Date.Sub(timeTestDate).Nanoseconds() < 0,
-			FifoBuy:    newEmptyValueAndFee(DEFAULT_CURRENCY),
-			Revenue:    newEmptyValueAndFee(DEFAULT_CURRENCY),
+			FifoBuy: newEmptyValueAndFee(DEFAULT_CURRENCY),
+			Revenue: newEmptyValueAndFee(DEFAULT_CURRENCY),
 		}
-		soldBuyItemRatio := 0.0
+		soldItem.TimeTested, soldItem.TimeTestRule = yearRules.EvaluateTimeTest(itemToSell.buyItem.Date, sellOp.SellItem.Date)
 		newAvailableQuantity := itemToSell.availableQuantity - quantityToBeSold
 		if newAvailableQuantity >= 0.0 {
 			// sell operation has all buys processed
 			soldItem.SoldQuantity = quantityToBeSold
 			itemToSell.availableQuantity = newAvailableQuantity
-			soldBuyItemRatio = soldItem.SoldQuantity / itemToSell.buyItem.Quantity
 		} else {
 			// some buy items are still required to be sold by this sell operation
 			quantityToBeSold -= itemToSell.availableQuantity
 			soldItem.SoldQuantity = itemToSell.availableQuantity
 			// noting remains to be sold in the buy item
 			itemToSell.availableQuantity = 0.0
-			soldBuyItemRatio = soldItem.SoldQuantity / itemToSell.buyItem.Quantity
 		}
 
 		// calculate purchase for this item
-		soldItem.FifoBuy.Value = newAccountingValue(
-			soldBuyItemRatio*itemToSell.buyItem.BankAmount*itemToSell.buyItem.DayExchangeRate,
-			soldBuyItemRatio*itemToSell.buyItem.BankAmount*itemToSell.buyItem.YearExchangeRate,
-			DEFAULT_CURRENCY)
-		soldItem.FifoBuy.Fee = newAccountingValue(
-			soldBuyItemRatio*itemToSell.buyItem.Fee*itemToSell.buyItem.DayExchangeRate,
-			soldBuyItemRatio*itemToSell.buyItem.Fee*itemToSell.buyItem.YearExchangeRate,
-			DEFAULT_CURRENCY)
+		soldItem.FifoBuy = itemToSell.unitPrice.MultiplyNew(soldItem.SoldQuantity)
 		// calculate revenue for this buy item
 		soldRatio := soldItem.SoldQuantity / sellOp.SellItem.Quantity
 		soldItem.Revenue.Value = newAccountingValue(
@@ -219,6 +331,50 @@ func calculateSellExpense(sellOp *SellOperation, availableBuyItems ItemsToSell,
 			return
 		}
 	}
+
+	if quantityToBeSold > quantityTolerance {
+		sellOp.OversoldQuantity = quantityToBeSold
+	}
+}
+
+// Weighted arithmetic average method - all items held in the day of sell share the same (average) purchase price.
+// The average is recalculated with every sell (including buys made since the previous one) and items are consumed
+// in FIFO order only to evaluate the time test.
+func calculateSellAverageExpense(sellOp *SellOperation, availableBuyItems ItemsToSell, yearRules *YearRules) {
+	heldBuyItems := filterItemsToSell(availableBuyItems, func(itemToSell *ItemToSell) bool {
+		return !itemToSell.buyItem.Date.After(sellOp.SellItem.Date)
+	})
+
+	heldQuantity := 0.0
+	heldPrice := newEmptyValueAndFee(DEFAULT_CURRENCY)
+	for _, itemToSell := range heldBuyItems {
+		heldQuantity += itemToSell.availableQuantity
+		heldPrice.Add(itemToSell.unitPrice.MultiplyNew(itemToSell.availableQuantity))
+	}
+	if heldQuantity > 0.0 {
+		averagePrice := heldPrice.MultiplyNew(1 / heldQuantity)
+		for _, itemToSell := range heldBuyItems {
+			// own copy, the unit price of an item may be rescaled later (e.g. by a split)
+			itemToSell.unitPrice = averagePrice.MultiplyNew(1)
+		}
+		log.Debugf("sell '%s' average unit price: %v", sellOp.SellItem.Name, averagePrice)
+	}
+
+	calculateSellExpense(sellOp, heldBuyItems, yearRules)
+}
+
+// LIFO method - the latest item bought before the sell is sold first
+func calculateSellLifoExpense(sellOp *SellOperation, availableBuyItems ItemsToSell, yearRules *YearRules) {
+	heldBuyItems := filterItemsToSell(availableBuyItems, func(itemToSell *ItemToSell) bool {
+		return !itemToSell.buyItem.Date.After(sellOp.SellItem.Date)
+	})
+
+	reversedBuyItems := make(ItemsToSell, 0, len(heldBuyItems))
+	for i := len(heldBuyItems) - 1; i >= 0; i-- {
+		reversedBuyItems = append(reversedBuyItems, heldBuyItems[i])
+	}
+
+	calculateSellExpense(sellOp, reversedBuyItems, yearRules)
 }
 
 func getTransactionsInYear(transactions ingest.TransactionLogItems, from time.Time, to time.Time) (ret ingest.TransactionLogItems) {
@@ -230,6 +386,24 @@ func getTransactionsInYear(transactions ingest.TransactionLogItems, from time.Ti
 	return filterTransactionLog(transactions, isTransactionTimestampBetween)
 }
 
+// tax refunds of dividends paid in the period (the refund itself may come later)
+func getTaxRefundsOfDividendsInYear(taxRefunds ingest.TransactionLogItems, from time.Time, to time.Time) ingest.TransactionLogItems {
+	isDividendTimestampBetween := func(item *ingest.TransactionLogItem) bool {
+		return item.RelatedItem != nil && !item.RelatedItem.Date.Before(from) && !item.RelatedItem.Date.After(to)
+	}
+	return filterTransactionLog(taxRefunds, isDividendTimestampBetween)
+}
+
+// sum of tax refunded for the dividend
+func getRefundedTax(taxRefunds ingest.TransactionLogItems, dividend *ingest.TransactionLogItem) (ret float64) {
+	for _, taxRefund := range taxRefunds {
+		if taxRefund.RelatedItem == dividend {
+			ret += taxRefund.BrokerAmount
+		}
+	}
+	return
+}
+
 func filterTransactionLog(list ingest.TransactionLogItems, test func(*ingest.TransactionLogItem) bool) (ret ingest.TransactionLogItems) {
 	for _, item := range list {
 		if test(item) {
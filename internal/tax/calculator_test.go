@@ -0,0 +1,272 @@
+package tax
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/marty-cz/czech-tax-calculator/internal/ingest"
+	"github.com/marty-cz/czech-tax-calculator/internal/util"
+)
+
+func createDate(day, month, year int) time.Time {
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func createItem(operation ingest.TransactionType, name string, date time.Time, quantity, amount float64) *ingest.TransactionLogItem {
+	return &ingest.TransactionLogItem{
+		Name:               name,
+		Date:               date,
+		ItemPrice:          amount / quantity,
+		BankAmount:         amount,
+		OriginalBankAmount: amount,
+		BrokerAmount:       amount,
+		Quantity:           quantity,
+		Broker:             "broker",
+		Currency:           util.CZK,
+		DayExchangeRate:    1.0,
+		YearExchangeRate:   1.0,
+		Operation:          operation,
+	}
+}
+
+func TestCalculateOversell(t *testing.T) {
+	newTransactions := func() *ingest.TransactionLog {
+		return &ingest.TransactionLog{
+			Purchases: ingest.TransactionLogItems{
+				createItem(ingest.BUY, "AAA", createDate(1, 2, 2021), 10, 1000),
+				// bought after the sell, it can not cover it
+				createItem(ingest.BUY, "AAA", createDate(1, 6, 2021), 10, 1000),
+			},
+			Sales: ingest.TransactionLogItems{
+				createItem(ingest.SELL, "AAA", createDate(1, 4, 2021), 15, 3000),
+			},
+		}
+	}
+	for _, strategy := range CostBasisStrategies {
+		t.Run(strategy.Name(), func(t *testing.T) {
+			if _, err := Calculate(newTransactions(), "2021", StockRules, strategy, false, false, false); err == nil {
+				t.Errorf("Calculate() expected error for oversold sell")
+			}
+			reports, err := Calculate(newTransactions(), "2021", StockRules, strategy, true, false, false)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			oversold := reports[0].SellOperations.GetOversold()
+			if len(oversold) != 1 || !util.EqWithTolerance(oversold[0].OversoldQuantity, 5, 0.0001) {
+				t.Errorf("GetOversold() = %v, want single sell with missing quantity 5", oversold)
+			}
+		})
+	}
+}
+
+func TestCalculateCryptoRules(t *testing.T) {
+	newTransactions := func(sellYear int) *ingest.TransactionLog {
+		return &ingest.TransactionLog{
+			Purchases: ingest.TransactionLogItems{
+				createItem(ingest.BUY, "BTC", createDate(1, 2, 2020), 1, 10000),
+				createItem(ingest.BUY, "BTC", createDate(1, 2, sellYear), 1, 10000),
+			},
+			Sales: ingest.TransactionLogItems{
+				createItem(ingest.SELL, "BTC", createDate(1, 6, sellYear), 2, 150000),
+			},
+		}
+	}
+	tests := []struct {
+		name                  string
+		sellYear              int
+		wantTimeTestedRevenue float64
+		wantExemption         bool
+		wantExempt            bool
+	}{
+		{"Before 2025 - no time test, no exemption", 2024, 0, false, false},
+		{"Since 2025 - time test and exemption", 2025, 75000, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports, err := Calculate(newTransactions(tt.sellYear), fmt.Sprint(tt.sellYear), CryptoRules, FIFO, false, false, false)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			report := reports[len(reports)-1]
+			if got := report.TimeTestedItemRevenue.ValueWithDayExchangeRate; !util.EqWithTolerance(got, tt.wantTimeTestedRevenue, 0.0001) {
+				t.Errorf("Calculate() time tested revenue = %v, want %v", got, tt.wantTimeTestedRevenue)
+			}
+			if (report.RevenueExemption != nil) != tt.wantExemption {
+				t.Fatalf("Calculate() exemption = %v, want exemption %v", report.RevenueExemption, tt.wantExemption)
+			}
+			if tt.wantExemption && report.RevenueExemption.ExemptWithDayExchangeRate != tt.wantExempt {
+				t.Errorf("Calculate() exempt = %v, want %v", report.RevenueExemption.ExemptWithDayExchangeRate, tt.wantExempt)
+			}
+		})
+	}
+}
+
+func TestCalculateReportDomesticDividends(t *testing.T) {
+	newDividend := func(country string, amount, paidTax float64) *ingest.TransactionLogItem {
+		item := createItem(ingest.DIVIDEND, "AAA", createDate(1, 6, 2022), 1, amount)
+		item.Country = country
+		item.PaidTax = paidTax
+		item.OriginalPaidTax = paidTax
+		return item
+	}
+	dividends := ingest.TransactionLogItems{
+		newDividend("USA", 1_000, 150),
+		newDividend("CZECHIA", 2_000, 300),
+		newDividend("CZECHIA", 1_000, 150),
+	}
+	report := calculateReport(SellOperations{}, dividends, ingest.TransactionLogItems{}, ingest.TransactionLogItems{}, ingest.TransactionLogItems{}, ingest.TransactionLogItems{}, ingest.TransactionLogItems{}, createDate(1, 1, 2022))
+
+	if got := report.GetDividendRevenue().ValueWithDayExchangeRate; got != 1_000 {
+		t.Errorf("GetDividendRevenue() = %v, want 1000", got)
+	}
+	if _, exist := report.DividendReports["CZECHIA"]; exist {
+		t.Errorf("DividendReports contains domestic dividends")
+	}
+	domestic, exist := report.DomesticDividendReports.Get("broker")
+	if !exist || domestic.RawRevenue.Value.ValueWithDayExchangeRate != 3_000 || domestic.PaidTax.ValueWithDayExchangeRate != 450 {
+		t.Errorf("DomesticDividendReports = %v, want revenue 3000 and paid tax 450", report.DomesticDividendReports)
+	}
+}
+
+func TestCalculateReportInterests(t *testing.T) {
+	newInterest := func(country string, amount, paidTax float64) *ingest.TransactionLogItem {
+		item := createItem(ingest.INTEREST, "CASH", createDate(1, 6, 2022), 1, amount)
+		item.Country = country
+		item.PaidTax = paidTax
+		item.OriginalPaidTax = paidTax
+		return item
+	}
+	dividend := createItem(ingest.DIVIDEND, "AAA", createDate(1, 6, 2022), 1, 1_000)
+	dividend.Country = "USA"
+	interests := ingest.TransactionLogItems{
+		newInterest("IRELAND", 500, 0),
+		newInterest("USA", 200, 20),
+		newInterest("CZECHIA", 300, 45),
+	}
+	interests[2].PaidTaxMismatch = 5
+	report := calculateReport(SellOperations{}, ingest.TransactionLogItems{dividend}, interests, ingest.TransactionLogItems{}, ingest.TransactionLogItems{}, ingest.TransactionLogItems{}, ingest.TransactionLogItems{}, createDate(1, 1, 2022))
+
+	if got := report.GetInterestRevenue().ValueWithDayExchangeRate; got != 700 {
+		t.Errorf("GetInterestRevenue() = %v, want 700", got)
+	}
+	if got := report.GetDividendRevenue().ValueWithDayExchangeRate; got != 1_000 {
+		t.Errorf("GetDividendRevenue() = %v, want 1000", got)
+	}
+	if _, exist := report.DomesticInterestReports.Get("broker"); !exist {
+		t.Errorf("DomesticInterestReports = %v, want domestic interest", report.DomesticInterestReports)
+	}
+	if len(report.PaidTaxMismatches) != 1 || report.PaidTaxMismatches[0] != interests[2] {
+		t.Errorf("PaidTaxMismatches = %v, want the domestic interest", report.PaidTaxMismatches)
+	}
+	if got := report.GetIncomeCategoryBases()[CAPITAL_INCOME].Base.ValueWithDayExchangeRate; got != 1_700 {
+		t.Errorf("GetIncomeCategoryBases() capital income base = %v, want 1700", got)
+	}
+	credits := CalculateForeignTaxCredits(newAccountingValue(1_700, 1_700, DEFAULT_CURRENCY), newAccountingValue(255, 255, DEFAULT_CURRENCY), report)
+	if len(credits) != 2 || credits[1].Country != "USA" || credits[1].Income.ValueWithDayExchangeRate != 1_200 || credits[1].PaidTax.ValueWithDayExchangeRate != 20 {
+		t.Errorf("CalculateForeignTaxCredits() = %v, want IRELAND and USA with income 1200 and paid tax 20", credits)
+	}
+}
+
+func TestCalculateReportTaxRefunds(t *testing.T) {
+	dividend := createItem(ingest.DIVIDEND, "AAA", createDate(1, 6, 2022), 1, 1_000)
+	dividend.Country = "GERMANY"
+	dividend.PaidTax = 150
+	dividend.OriginalPaidTax = 263.75
+	newRefund := func(date time.Time, amount float64) *ingest.TransactionLogItem {
+		item := createItem(ingest.TAX_REFUND, "AAA", date, 1, amount)
+		item.RelatedItem = dividend
+		return item
+	}
+	tests := []struct {
+		name          string
+		refunds       ingest.TransactionLogItems
+		wantPaidTax   float64
+		wantExcessTax float64
+		wantRefundTax float64
+		wantOrigPaid  float64
+	}{
+		{"no refund", ingest.TransactionLogItems{}, 150, 113.75, 0, 263.75},
+		{"excess refunded next year", ingest.TransactionLogItems{newRefund(createDate(1, 3, 2023), 113.75)}, 150, 0, 113.75, 150},
+		{"refund above excess", ingest.TransactionLogItems{newRefund(createDate(1, 3, 2023), 100), newRefund(createDate(1, 4, 2024), 63.75)}, 100, 0, 163.75, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refunds := getTaxRefundsOfDividendsInYear(tt.refunds, createDate(1, 1, 2022), createDate(31, 12, 2022))
+			if len(refunds) != len(tt.refunds) {
+				t.Fatalf("getTaxRefundsOfDividendsInYear() = %v, want all refunds in dividend year", refunds)
+			}
+			report := calculateReport(SellOperations{}, ingest.TransactionLogItems{dividend}, ingest.TransactionLogItems{}, refunds, ingest.TransactionLogItems{}, ingest.TransactionLogItems{}, ingest.TransactionLogItems{}, createDate(1, 1, 2022))
+			got := (*report.DividendReports["GERMANY"])["broker"]
+			if !util.EqWithTolerance(got.PaidTax.ValueWithDayExchangeRate, tt.wantPaidTax, 1e-9) ||
+				!util.EqWithTolerance(got.ExcessPaidTax.ValueWithDayExchangeRate, tt.wantExcessTax, 1e-9) ||
+				!util.EqWithTolerance(got.RefundedTax.ValueWithDayExchangeRate, tt.wantRefundTax, 1e-9) ||
+				!util.EqWithTolerance(got.OriginalPaidTax.ValueWithDayExchangeRate, tt.wantOrigPaid, 1e-9) {
+				t.Errorf("calculateReport() dividend = %v, want paid tax %v, excess %v, refunded %v", got, tt.wantPaidTax, tt.wantExcessTax, tt.wantRefundTax)
+			}
+		})
+	}
+}
+
+func TestCalculateRewards(t *testing.T) {
+	reward := createItem(ingest.REWARD, "ETH", createDate(1, 3, 2025), 2, 10_000)
+	acquisition := createItem(ingest.BUY, "ETH", createDate(1, 3, 2025), 2, 10_000)
+	reward.RelatedItem = acquisition
+	transactions := &ingest.TransactionLog{
+		Purchases: ingest.TransactionLogItems{acquisition},
+		Sales: ingest.TransactionLogItems{
+			createItem(ingest.SELL, "ETH", createDate(1, 6, 2025), 1, 7_000),
+		},
+		Rewards: ingest.TransactionLogItems{reward},
+	}
+	reports, err := Calculate(transactions, "2025", CryptoRules, FIFO, false, false, false)
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	report := reports[len(reports)-1]
+	if got := report.RewardRevenue.ValueWithDayExchangeRate; got != 10_000 {
+		t.Errorf("RewardRevenue = %v, want 10000", got)
+	}
+	if got := report.TotalItemFifoExpense.Value.ValueWithDayExchangeRate; !util.EqWithTolerance(got, 5_000, 1e-6) {
+		t.Errorf("TotalItemFifoExpense = %v, want 5000 (fair value of rewarded items)", got)
+	}
+}
+
+func TestCalculatePerBrokerPools(t *testing.T) {
+	newTransactions := func() *ingest.TransactionLog {
+		buyX := createItem(ingest.BUY, "AAA", createDate(1, 1, 2020), 10, 1000)
+		buyX.Broker = "X"
+		buyY := createItem(ingest.BUY, "AAA", createDate(1, 1, 2021), 10, 3000)
+		buyY.Broker = "Y"
+		sellY := createItem(ingest.SELL, "AAA", createDate(1, 1, 2022), 10, 5000)
+		sellY.Broker = "Y"
+		return &ingest.TransactionLog{
+			Purchases: ingest.TransactionLogItems{buyX, buyY},
+			Sales:     ingest.TransactionLogItems{sellY},
+			Transfers: ingest.TransactionLogItems{
+				{Name: "AAA", Date: createDate(1, 6, 2021), Quantity: 5, Broker: "X", TargetBroker: "Y", Operation: ingest.TRANSFER},
+			},
+		}
+	}
+	tests := []struct {
+		name           string
+		perBrokerPools bool
+		wantExpense    float64
+	}{
+		// oldest buy at any broker
+		{"across brokers", false, 1000},
+		// 5 items transferred from X (bought 2020) and 5 items bought at Y
+		{"per broker", true, 500 + 1500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports, err := Calculate(newTransactions(), "2022", StockRules, FIFO, false, false, tt.perBrokerPools)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			if got := reports[len(reports)-1].TotalItemFifoExpense.Value.ValueWithDayExchangeRate; !util.EqWithTolerance(got, tt.wantExpense, 1e-6) {
+				t.Errorf("Calculate() expense = %v, want %v", got, tt.wantExpense)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package tax
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marty-cz/czech-tax-calculator/internal/ingest"
+	log "github.com/sirupsen/logrus"
+)
+
+// Applies corporate actions and transfers effective till the date (inclusive) to items bought before the action
+// and returns the actions which are not applied yet together with sells of cash received in mergers.
+// Actions have to be sorted by date.
+func applyCorporateActions(actions ingest.TransactionLogItems, itemsToSell *ItemsToSell, until time.Time, yearRules *YearRules) (ingest.TransactionLogItems, SellOperations) {
+	var sellOps SellOperations
+	for len(actions) > 0 && !actions[0].Date.After(until) {
+		action := actions[0]
+		switch action.Operation {
+		case ingest.SPLIT, ingest.REVERSE_SPLIT:
+			applySplit(action, *itemsToSell)
+		case ingest.SYMBOL_CHANGE:
+			applySymbolChange(action, *itemsToSell)
+		case ingest.MERGER:
+			if sellOp := applyMerger(action, *itemsToSell, yearRules); sellOp != nil {
+				sellOps = append(sellOps, sellOp)
+			}
+		case ingest.SPIN_OFF:
+			applySpinOff(action, itemsToSell)
+		case ingest.TRANSFER:
+			applyTransfer(action, itemsToSell)
+		default:
+			log.Warnf("unsupported corporate action '%v' of '%s' - skipping", action.Operation, action.Name)
+		}
+		actions = actions[1:]
+	}
+	return actions, sellOps
+}
+
+// rescales quantity and unit price of held items (acquisition date stays for the time test)
+func applySplit(action *ingest.TransactionLogItem, itemsToSell ItemsToSell) {
+	count := 0
+	for _, itemToSell := range getHeldItemsBefore(itemsToSell, action.Name, action.Date) {
+		itemToSell.availableQuantity *= action.Ratio
+		itemToSell.unitPrice = itemToSell.unitPrice.MultiplyNew(1 / action.Ratio)
+		count++
+	}
+	log.Infof("split of '%s' with ratio %v at %s applied to %d held item(s)", action.Name, action.Ratio, action.Date.Format("02.01.2006"), count)
+}
+
+// renames held items (and rescales them when the ratio is not 1)
+func applySymbolChange(action *ingest.TransactionLogItem, itemsToSell ItemsToSell) {
+	count := 0
+	for _, itemToSell := range getHeldItemsBefore(itemsToSell, action.Name, action.Date) {
+		itemToSell.name = action.NewName
+		itemToSell.availableQuantity *= action.Ratio
+		itemToSell.unitPrice = itemToSell.unitPrice.MultiplyNew(1 / action.Ratio)
+		count++
+	}
+	log.Infof("symbol change of '%s' to '%s' at %s applied to %d held item(s)", action.Name, action.NewName, action.Date.Format("02.01.2006"), count)
+}
+
+// Exchanges held items for shares of the new item and/or cash. The cost share of purchase price is carried over
+// to the new shares (with the original acquisition date), the rest of it is an expense of sell of the cash part.
+func applyMerger(action *ingest.TransactionLogItem, itemsToSell ItemsToSell, yearRules *YearRules) (sellOp *SellOperation) {
+	heldItems := getHeldItemsBefore(itemsToSell, action.Name, action.Date)
+	heldQuantity := 0.0
+	for _, itemToSell := range heldItems {
+		heldQuantity += itemToSell.availableQuantity
+	}
+
+	if action.ItemPrice > 0 && heldQuantity > 0 {
+		// each held item is sold by its part of purchase price not carried over to the new shares
+		cashItemsToSell := make(ItemsToSell, 0, len(heldItems))
+		for _, itemToSell := range heldItems {
+			cashItemsToSell = append(cashItemsToSell, &ItemToSell{
+				buyItem:           itemToSell.buyItem,
+				name:              itemToSell.name,
+				broker:            itemToSell.broker,
+				availableQuantity: itemToSell.availableQuantity * (1 - action.CostShare),
+				soldByItems:       ingest.TransactionLogItems{},
+				unitPrice:         itemToSell.unitPrice.MultiplyNew(1),
+			})
+		}
+		cashAmount := action.ItemPrice * heldQuantity
+		sellOp = convertToSellOperations(ingest.TransactionLogItems{{
+			Name:               action.Name,
+			Date:               action.Date,
+			ItemPrice:          cashAmount / (heldQuantity * (1 - action.CostShare)),
+			BankAmount:         cashAmount,
+			OriginalBankAmount: cashAmount,
+			BrokerAmount:       cashAmount,
+			Quantity:           heldQuantity * (1 - action.CostShare),
+			Currency:           action.Currency,
+			DayExchangeRate:    action.DayExchangeRate,
+			YearExchangeRate:   action.YearExchangeRate,
+			Operation:          ingest.SELL,
+			SheetName:          action.SheetName,
+			SheetRow:           action.SheetRow,
+		}})[0]
+		calculateSellExpense(sellOp, cashItemsToSell, yearRules)
+	}
+
+	for _, itemToSell := range heldItems {
+		if action.Ratio <= 0 {
+			// merger for cash only
+			itemToSell.availableQuantity = 0
+			continue
+		}
+		itemToSell.name = action.NewName
+		itemToSell.availableQuantity *= action.Ratio
+		itemToSell.unitPrice = itemToSell.unitPrice.MultiplyNew(action.CostShare / action.Ratio)
+	}
+	log.Infof("merger of '%s' to '%s' (ratio %v, cash %v per item) at %s applied to %d held item(s)",
+		action.Name, action.NewName, action.Ratio, action.ItemPrice, action.Date.Format("02.01.2006"), len(heldItems))
+	return
+}
+
+// Creates items of the spun-off item from held items. The cost share of purchase price moves to the new items
+// which keep the original acquisition date.
+func applySpinOff(action *ingest.TransactionLogItem, itemsToSell *ItemsToSell) {
+	heldItems := getHeldItemsBefore(*itemsToSell, action.Name, action.Date)
+	for _, itemToSell := range heldItems {
+		*itemsToSell = append(*itemsToSell, &ItemToSell{
+			buyItem:           itemToSell.buyItem,
+			name:              action.NewName,
+			broker:            itemToSell.broker,
+			availableQuantity: itemToSell.availableQuantity * action.Ratio,
+			soldByItems:       ingest.TransactionLogItems{},
+			unitPrice:         itemToSell.unitPrice.MultiplyNew(action.CostShare / action.Ratio),
+		})
+		itemToSell.unitPrice = itemToSell.unitPrice.MultiplyNew(1 - action.CostShare)
+	}
+	sortItemsToSellByBuyDate(*itemsToSell)
+	log.Infof("spin-off of '%s' from '%s' at %s applied to %d held item(s)", action.NewName, action.Name, action.Date.Format("02.01.2006"), len(heldItems))
+}
+
+// Moves held items from one broker to another (oldest first) without any taxable event. Items keep the original
+// acquisition date and price, partially transferred item is split.
+func applyTransfer(action *ingest.TransactionLogItem, itemsToSell *ItemsToSell) {
+	quantityToTransfer := action.Quantity
+	heldItems := filterItemsToSell(*itemsToSell, func(itemToSell *ItemToSell) bool {
+		return strings.EqualFold(itemToSell.name, action.Name) && strings.EqualFold(itemToSell.broker, action.Broker) &&
+			itemToSell.availableQuantity > 0.0 && !itemToSell.buyItem.Date.After(action.Date)
+	})
+	for _, itemToSell := range heldItems {
+		if quantityToTransfer <= quantityTolerance {
+			break
+		}
+		if itemToSell.availableQuantity <= quantityToTransfer+quantityTolerance {
+			quantityToTransfer -= itemToSell.availableQuantity
+			itemToSell.broker = action.TargetBroker
+			continue
+		}
+		*itemsToSell = append(*itemsToSell, &ItemToSell{
+			buyItem:           itemToSell.buyItem,
+			name:              itemToSell.name,
+			broker:            action.TargetBroker,
+			availableQuantity: quantityToTransfer,
+			soldByItems:       ingest.TransactionLogItems{},
+			unitPrice:         itemToSell.unitPrice.MultiplyNew(1),
+		})
+		itemToSell.availableQuantity -= quantityToTransfer
+		quantityToTransfer = 0
+	}
+	sortItemsToSellByBuyDate(*itemsToSell)
+	if quantityToTransfer > quantityTolerance {
+		log.Warnf("transfer of '%s' from '%s' to '%s' at %s (sheet '%s' row '%d') misses quantity %v in held items",
+			action.Name, action.Broker, action.TargetBroker, action.Date.Format("02.01.2006"), action.SheetName, action.SheetRow, quantityToTransfer)
+	}
+}
+
+// keeps items ordered by acquisition date for FIFO
+func sortItemsToSellByBuyDate(itemsToSell ItemsToSell) {
+	sort.SliceStable(itemsToSell, func(i, j int) bool {
+		return itemsToSell[i].buyItem.Date.Before(itemsToSell[j].buyItem.Date)
+	})
+}
+
+// Items of the name bought before the date and not sold yet. The action date is its ex-date, so items bought
+// in the day of the action are already traded after it (e.g. in post-split quantity) and the action skips them.
+func getHeldItemsBefore(itemsToSell ItemsToSell, name string, date time.Time) ItemsToSell {
+	test := func(itemToSell *ItemToSell) bool {
+		return strings.EqualFold(itemToSell.name, name) && itemToSell.availableQuantity > 0.0 && itemToSell.buyItem.Date.Before(date)
+	}
+	return filterItemsToSell(itemsToSell, test)
+}
@@ -0,0 +1,121 @@
+package tax
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marty-cz/czech-tax-calculator/internal/ingest"
+	"github.com/marty-cz/czech-tax-calculator/internal/util"
+)
+
+func TestCalculateSplits(t *testing.T) {
+	newCorporateAction := func(operation ingest.TransactionType, name string, date time.Time, ratio float64) *ingest.TransactionLogItem {
+		return &ingest.TransactionLogItem{Name: name, Date: date, Operation: operation, Ratio: ratio}
+	}
+	transactions := &ingest.TransactionLog{
+		Purchases: ingest.TransactionLogItems{
+			createItem(ingest.BUY, "AAA", createDate(1, 2, 2018), 10, 1000),
+			createItem(ingest.BUY, "AAA", createDate(1, 3, 2021), 40, 2000),
+			createItem(ingest.BUY, "BBB", createDate(1, 3, 2019), 30, 3000),
+		},
+		Sales: ingest.TransactionLogItems{
+			createItem(ingest.SELL, "AAA", createDate(1, 4, 2020), 2, 400),
+			createItem(ingest.SELL, "AAA", createDate(1, 4, 2022), 52, 5200),
+			createItem(ingest.SELL, "BBB", createDate(1, 4, 2022), 10, 4000),
+		},
+		CorporateActions: ingest.TransactionLogItems{
+			newCorporateAction(ingest.SPLIT, "AAA", createDate(1, 1, 2021), 4),
+			newCorporateAction(ingest.REVERSE_SPLIT, "BBB", createDate(1, 1, 2021), 1.0/3),
+		},
+	}
+	reports, err := Calculate(transactions, "2022", StockRules, FIFO, false, false, false)
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	report := reports[len(reports)-1]
+	if len(report.SellOperations.GetOversold()) > 0 {
+		t.Fatalf("Calculate() oversold = %v, want none", report.SellOperations.GetOversold())
+	}
+	// AAA: 8 items (32 after split) bought in 2018 for 800 are time tested, 20 items bought in 2021 for 1000 are not
+	// BBB: 10 items (30 before reverse split) bought in 2019 for 3000 are time tested
+	if got := report.TimeTestedItemFifoExpense.Value.ValueWithDayExchangeRate; !util.EqWithTolerance(got, 3_800, 1e-6) {
+		t.Errorf("TimeTestedItemFifoExpense = %v, want 3800", got)
+	}
+	if got := report.TotalItemFifoExpense.Value.ValueWithDayExchangeRate; !util.EqWithTolerance(got, 4_800, 1e-6) {
+		t.Errorf("TotalItemFifoExpense = %v, want 4800", got)
+	}
+}
+
+func TestCalculateSplitOnBuyDay(t *testing.T) {
+	transactions := &ingest.TransactionLog{
+		Purchases: ingest.TransactionLogItems{
+			createItem(ingest.BUY, "AAA", createDate(1, 1, 2021), 10, 1000),
+			// bought in the ex-date of the split in post-split quantity
+			createItem(ingest.BUY, "AAA", createDate(1, 6, 2021), 4, 400),
+		},
+		Sales: ingest.TransactionLogItems{
+			createItem(ingest.SELL, "AAA", createDate(1, 6, 2021), 24, 2400),
+		},
+		CorporateActions: ingest.TransactionLogItems{
+			{Name: "AAA", Date: createDate(1, 6, 2021), Operation: ingest.SPLIT, Ratio: 2},
+		},
+	}
+	reports, err := Calculate(transactions, "2021", StockRules, FIFO, false, false, false)
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	// 20 items (10 before split) for 1000 and 4 items of the same day (not split) for 400
+	if got := reports[0].TotalItemFifoExpense.Value.ValueWithDayExchangeRate; !util.EqWithTolerance(got, 1_400, 1e-6) {
+		t.Errorf("TotalItemFifoExpense = %v, want 1400", got)
+	}
+}
+
+func TestCalculateSymbolChangeMergerSpinOff(t *testing.T) {
+	newCorporateAction := func(operation ingest.TransactionType, name, newName string, date time.Time, ratio, cash, costShare float64) *ingest.TransactionLogItem {
+		return &ingest.TransactionLogItem{Name: name, NewName: newName, Date: date, Operation: operation, Ratio: ratio, ItemPrice: cash, CostShare: costShare,
+			Currency: util.CZK, DayExchangeRate: 1.0, YearExchangeRate: 1.0}
+	}
+	transactions := &ingest.TransactionLog{
+		Purchases: ingest.TransactionLogItems{
+			createItem(ingest.BUY, "FB", createDate(1, 1, 2018), 10, 1000),
+			createItem(ingest.BUY, "AAA", createDate(1, 1, 2021), 10, 1000),
+			createItem(ingest.BUY, "CCC", createDate(1, 1, 2021), 10, 1000),
+		},
+		Sales: ingest.TransactionLogItems{
+			createItem(ingest.SELL, "DDD", createDate(1, 4, 2022), 5, 500),
+			createItem(ingest.SELL, "CCC", createDate(1, 4, 2022), 10, 1000),
+			createItem(ingest.SELL, "BBB", createDate(1, 5, 2022), 20, 1000),
+			createItem(ingest.SELL, "META", createDate(1, 7, 2022), 10, 2000),
+		},
+		CorporateActions: ingest.TransactionLogItems{
+			newCorporateAction(ingest.SPIN_OFF, "CCC", "DDD", createDate(1, 2, 2022), 0.5, 0, 0.2),
+			newCorporateAction(ingest.MERGER, "AAA", "BBB", createDate(1, 3, 2022), 2, 30, 0.7),
+			newCorporateAction(ingest.SYMBOL_CHANGE, "FB", "META", createDate(9, 6, 2022), 1, 0, 1),
+		},
+	}
+	reports, err := Calculate(transactions, "2022", StockRules, FIFO, false, false, false)
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	report := reports[len(reports)-1]
+	if len(report.SellOperations) != 5 {
+		t.Fatalf("SellOperations count = %d, want 4 sells and 1 cash of merger", len(report.SellOperations))
+	}
+	wantExpenses := map[string]float64{"DDD": 200, "CCC": 800, "AAA": 300, "BBB": 700, "META": 1000}
+	for _, sellOp := range report.SellOperations {
+		expense := 0.0
+		for _, soldItem := range sellOp.SoldItems {
+			expense += soldItem.FifoBuy.Value.ValueWithDayExchangeRate
+		}
+		if !util.EqWithTolerance(expense, wantExpenses[sellOp.SellItem.Name], 1e-6) {
+			t.Errorf("sell of '%s' expense = %v, want %v", sellOp.SellItem.Name, expense, wantExpenses[sellOp.SellItem.Name])
+		}
+	}
+	if got := report.TotalItemRevenue.ValueWithDayExchangeRate; !util.EqWithTolerance(got, 4_800, 1e-6) {
+		t.Errorf("TotalItemRevenue = %v, want 4800", got)
+	}
+	// only META (bought as FB in 2018) is time tested
+	if got := report.TimeTestedItemFifoExpense.Value.ValueWithDayExchangeRate; !util.EqWithTolerance(got, 1_000, 1e-6) {
+		t.Errorf("TimeTestedItemFifoExpense = %v, want 1000", got)
+	}
+}
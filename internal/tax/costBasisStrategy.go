@@ -0,0 +1,68 @@
+package tax
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CostBasisStrategy determines purchase price (cost basis) of items sold by a sell operation
+type CostBasisStrategy interface {
+	// name used to select the strategy
+	Name() string
+	// false for what-if strategies which are not allowed by Czech law (for comparison only)
+	IsFilingMethod() bool
+	// matches the sell operation with available buy items and fills its sold items
+	CalculateSellExpense(sellOp *SellOperation, availableBuyItems ItemsToSell, yearRules *YearRules)
+}
+
+type fifoStrategy struct{}
+
+func (fifoStrategy) Name() string         { return "fifo" }
+func (fifoStrategy) IsFilingMethod() bool { return true }
+func (fifoStrategy) CalculateSellExpense(sellOp *SellOperation, availableBuyItems ItemsToSell, yearRules *YearRules) {
+	calculateSellExpense(sellOp, availableBuyItems, yearRules)
+}
+
+type weightedAverageStrategy struct{}
+
+func (weightedAverageStrategy) Name() string         { return "average" }
+func (weightedAverageStrategy) IsFilingMethod() bool { return true }
+func (weightedAverageStrategy) CalculateSellExpense(sellOp *SellOperation, availableBuyItems ItemsToSell, yearRules *YearRules) {
+	calculateSellAverageExpense(sellOp, availableBuyItems, yearRules)
+}
+
+type lifoStrategy struct{}
+
+func (lifoStrategy) Name() string         { return "lifo" }
+func (lifoStrategy) IsFilingMethod() bool { return false }
+func (lifoStrategy) CalculateSellExpense(sellOp *SellOperation, availableBuyItems ItemsToSell, yearRules *YearRules) {
+	calculateSellLifoExpense(sellOp, availableBuyItems, yearRules)
+}
+
+var (
+	FIFO                CostBasisStrategy   = fifoStrategy{}
+	WEIGHTED_AVERAGE    CostBasisStrategy   = weightedAverageStrategy{}
+	LIFO                CostBasisStrategy   = lifoStrategy{}
+	CostBasisStrategies []CostBasisStrategy = []CostBasisStrategy{FIFO, WEIGHTED_AVERAGE, LIFO}
+)
+
+func GetCostBasisStrategyByName(name string) (CostBasisStrategy, error) {
+	aName := strings.TrimSpace(name)
+	for _, s := range CostBasisStrategies {
+		if strings.EqualFold(s.Name(), aName) {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported cost basis strategy '%s'", aName)
+}
+
+// human readable description of a strategy, what-if strategies are marked
+func GetCostBasisStrategyDescription(s CostBasisStrategy) string {
+	if s == nil {
+		s = FIFO
+	}
+	if !s.IsFilingMethod() {
+		return strings.ToUpper(s.Name()) + " (what-if only, NOT valid for tax filing)"
+	}
+	return strings.ToUpper(s.Name())
+}
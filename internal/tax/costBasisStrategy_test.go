@@ -0,0 +1,67 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/marty-cz/czech-tax-calculator/internal/ingest"
+	"github.com/marty-cz/czech-tax-calculator/internal/util"
+)
+
+func TestCalculateCostBasisStrategies(t *testing.T) {
+	newTransactions := func() *ingest.TransactionLog {
+		return &ingest.TransactionLog{
+			Purchases: ingest.TransactionLogItems{
+				createItem(ingest.BUY, "AAA", createDate(1, 2, 2021), 10, 1000),
+				createItem(ingest.BUY, "AAA", createDate(1, 3, 2021), 10, 2000),
+				createItem(ingest.BUY, "AAA", createDate(1, 5, 2021), 20, 6000),
+			},
+			Sales: ingest.TransactionLogItems{
+				createItem(ingest.SELL, "AAA", createDate(1, 4, 2021), 5, 1000),
+				createItem(ingest.SELL, "AAA", createDate(1, 6, 2021), 10, 4000),
+			},
+		}
+	}
+	tests := []struct {
+		name        string
+		strategy    CostBasisStrategy
+		wantExpense float64
+	}{
+		// 5 * 100 + 5 * 100 + 5 * 200
+		{"FIFO", FIFO, 2000},
+		// average 150 => 5 * 150, then (15 * 150 + 6000) / 35 => 10 * 235.714...
+		{"Weighted average", WEIGHTED_AVERAGE, 750 + 10*(15*150.0+6000)/35},
+		// 5 * 200, then 10 * 300
+		{"LIFO", LIFO, 4000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports, err := Calculate(newTransactions(), "2021", StockRules, tt.strategy, false, false, false)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			if len(reports) != 1 {
+				t.Fatalf("Calculate() reports count = %d, want 1", len(reports))
+			}
+			got := reports[0].TotalItemFifoExpense.Value.ValueWithDayExchangeRate
+			if !util.EqWithTolerance(got, tt.wantExpense, 0.0001) {
+				t.Errorf("Calculate() expense = %v, want %v", got, tt.wantExpense)
+			}
+		})
+	}
+}
+
+func TestCalculateSellAverageExpenseOwnUnitPrices(t *testing.T) {
+	itemsToSell := convertToItemsToSell(ingest.TransactionLogItems{
+		createItem(ingest.BUY, "AAA", createDate(1, 2, 2021), 10, 1000),
+		createItem(ingest.BUY, "AAA", createDate(1, 3, 2021), 10, 2000),
+	})
+	sellOp := convertToSellOperations(ingest.TransactionLogItems{createItem(ingest.SELL, "AAA", createDate(1, 4, 2021), 5, 1000)})[0]
+	calculateSellAverageExpense(sellOp, itemsToSell, StockRules(2021))
+	first, second := itemsToSell[0].unitPrice, itemsToSell[1].unitPrice
+	if first == second || first.Value == second.Value || first.Fee == second.Fee {
+		t.Fatalf("calculateSellAverageExpense() items share unit price %v", first)
+	}
+	if first.Value.ValueWithDayExchangeRate != 150 || second.Value.ValueWithDayExchangeRate != 150 {
+		t.Errorf("calculateSellAverageExpense() unit prices = %v, %v, want average 150", first, second)
+	}
+}
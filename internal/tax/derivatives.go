@@ -0,0 +1,259 @@
+package tax
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marty-cz/czech-tax-calculator/internal/ingest"
+	"github.com/marty-cz/czech-tax-calculator/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// part of an open derivative position - a trade which opened it (long by buy, short by sell)
+type openContract struct {
+	openItem          *ingest.TransactionLogItem
+	short             bool
+	availableQuantity float64
+}
+
+// derivative position closed (fully or partially) by an opposite trade or option expiry
+type ClosedPosition struct {
+	OpenItem  *ingest.TransactionLogItem
+	CloseItem *ingest.TransactionLogItem
+	Quantity  float64
+	Short     bool
+	// sell value of long position, premium (sell value) received by short position
+	Revenue *AccountingValue
+	// buy value of long position, value paid to cover short position
+	Expense *AccountingValue
+	// fees of opening and closing trades related to the quantity
+	Fee *AccountingValue
+}
+
+func (x *ClosedPosition) String() string {
+	return fmt.Sprintf("contract:%v quantity:%v short:%v open:%v close:%v revenue:(%v) expense:(%v) fee:(%v)",
+		x.OpenItem.Name, x.Quantity, x.Short, x.OpenItem.Date.Format("02.01.2006"), x.CloseItem.Date.Format("02.01.2006"), x.Revenue, x.Expense, x.Fee)
+}
+
+type ClosedPositions []*ClosedPosition
+
+// Premium of an assigned (exercised) option which adjusts price of the underlying trade instead of being taxed
+type UnderlyingAdjustment struct {
+	Underlying string
+	Broker     string
+	Date       time.Time
+	// operation of the underlying trade (BUY when call is exercised or put is assigned, SELL otherwise)
+	Operation ingest.TransactionType
+	// amount (in currency of the option) added to the price of the underlying trade (negative lowers it)
+	Amount   float64
+	Currency *util.Currency
+	Option   *ingest.TransactionLogItem
+}
+
+type UnderlyingAdjustments []*UnderlyingAdjustment
+
+// Matches derivative trades of a contract at a broker in FIFO order - a trade closes open positions of the opposite
+// side first and the rest of it opens a new position. Gain or loss is reported in the year of closing.
+// Premiums of assigned options are returned as adjustments of the underlying trades.
+func CalculateDerivatives(transactions *ingest.TransactionLog, currentTaxYearString string) (reports Reports, adjustments UnderlyingAdjustments, err error) {
+	currentTaxYear, err := util.GetYearFromString(currentTaxYearString)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	trades := append(ingest.TransactionLogItems{}, transactions.Derivatives...)
+	sort.Stable(ByDate(trades))
+	positions := make(map[string][]*openContract)
+	var closedPositions ClosedPositions
+	for _, trade := range trades {
+		key := strings.ToUpper(trade.Name) + "|" + strings.ToUpper(trade.Broker)
+		switch trade.Operation {
+		case ingest.BUY, ingest.SELL:
+			closed, remaining := closeContracts(positions[key], trade, trade.Quantity, trade.Operation == ingest.BUY)
+			closedPositions = append(closedPositions, closed...)
+			if remaining > quantityTolerance {
+				positions[key] = append(positions[key], &openContract{openItem: trade, short: trade.Operation == ingest.SELL, availableQuantity: remaining})
+			}
+		case ingest.EXPIRY, ingest.ASSIGNMENT:
+			short := len(positions[key]) > 0 && positions[key][0].short
+			closed, remaining := closeContracts(positions[key], trade, trade.Quantity, short)
+			if remaining > quantityTolerance {
+				return nil, nil, fmt.Errorf("%s of '%s' at %s (sheet '%s' row '%d') misses quantity %v in open positions",
+					getDerivativeActionName(trade.Operation), trade.Name, trade.Date.Format("02.01.2006"), trade.SheetName, trade.SheetRow, remaining)
+			}
+			if trade.Operation == ingest.EXPIRY {
+				closedPositions = append(closedPositions, closed...)
+			} else {
+				adjustments = append(adjustments, newUnderlyingAdjustment(trade, closed))
+			}
+		default:
+			log.Warnf("unsupported derivative operation '%v' of '%s' - skipping", trade.Operation, trade.Name)
+		}
+		positions[key] = removeClosedContracts(positions[key])
+	}
+
+	oldestYear := currentTaxYear
+	for _, closedPosition := range closedPositions {
+		if year := closedPosition.CloseItem.Date.Year(); year < oldestYear {
+			oldestYear = year
+		}
+	}
+	for year := oldestYear; year <= currentTaxYear; year++ {
+		dateStart := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		var inYearClosedPositions ClosedPositions
+		for _, closedPosition := range closedPositions {
+			if closedPosition.CloseItem.Date.Year() == year {
+				inYearClosedPositions = append(inYearClosedPositions, closedPosition)
+			}
+		}
+		report := calculateReport(SellOperations{}, nil, nil, nil, nil, nil, nil, dateStart)
+		for _, closedPosition := range inYearClosedPositions {
+			report.TotalItemRevenue.Add(closedPosition.Revenue)
+			report.TotalItemFifoExpense.Value.Add(closedPosition.Expense)
+			report.TotalItemFifoExpense.Fee.Add(closedPosition.Fee)
+		}
+		report.ClosedPositions = inYearClosedPositions
+		report.CostBasisStrategy = FIFO
+		report.Rules = DerivativeRules(year)
+		log.Infof("closed derivative positions count for year '%d': %d", year, len(inYearClosedPositions))
+		reports = append(reports, report)
+	}
+	return
+}
+
+// Closes open positions of the side (oldest first) by quantity of the trade and returns the rest of the quantity.
+// Expiry closes positions for nothing.
+func closeContracts(position []*openContract, trade *ingest.TransactionLogItem, quantity float64, short bool) (closed ClosedPositions, remaining float64) {
+	remaining = quantity
+	for _, contract := range position {
+		if remaining <= quantityTolerance {
+			break
+		}
+		if contract.short != short || contract.availableQuantity <= quantityTolerance {
+			continue
+		}
+		closedQuantity := min(contract.availableQuantity, remaining)
+		contract.availableQuantity -= closedQuantity
+		remaining -= closedQuantity
+
+		openValue := getDerivativeTradeValue(contract.openItem, closedQuantity)
+		closeValue := getDerivativeTradeValue(trade, closedQuantity)
+		if trade.Operation == ingest.EXPIRY {
+			closeValue = newAccountingValue(0, 0, DEFAULT_CURRENCY)
+		}
+		fee := getDerivativeTradeFee(contract.openItem, closedQuantity)
+		fee.Add(getDerivativeTradeFee(trade, closedQuantity))
+		closedPosition := &ClosedPosition{
+			OpenItem:  contract.openItem,
+			CloseItem: trade,
+			Quantity:  closedQuantity,
+			Short:     short,
+			Revenue:   closeValue,
+			Expense:   openValue,
+			Fee:       fee,
+		}
+		if short {
+			closedPosition.Revenue, closedPosition.Expense = openValue, closeValue
+		}
+		closed = append(closed, closedPosition)
+	}
+	return
+}
+
+// Premium of long option (paid) is added to the purchase price of exercised call or lowers the revenue of exercised put.
+// Premium of short option (received) lowers the purchase price of assigned put or is added to the revenue of assigned call.
+func newUnderlyingAdjustment(assignment *ingest.TransactionLogItem, closed ClosedPositions) *UnderlyingAdjustment {
+	adjustment := &UnderlyingAdjustment{
+		Underlying: assignment.Underlying,
+		Broker:     assignment.Broker,
+		Date:       assignment.Date,
+		Operation:  ingest.BUY,
+		Currency:   assignment.Currency,
+		Option:     assignment,
+	}
+	call := assignment.ContractType == ingest.CALL
+	for _, closedPosition := range closed {
+		openItem := closedPosition.OpenItem
+		ratio := closedPosition.Quantity / openItem.Quantity
+		premium := ratio * openItem.BrokerAmount
+		openFee := ratio * openItem.Fee
+		if closedPosition.Short {
+			// received premium lowered by the fee
+			premium = -(premium - openFee)
+		} else {
+			premium += openFee
+		}
+		if call == closedPosition.Short {
+			// assigned call or exercised put - underlying is sold
+			adjustment.Operation = ingest.SELL
+			premium = -premium
+		}
+		adjustment.Amount += premium
+	}
+	// fee of the assignment itself is an expense of the underlying trade
+	if adjustment.Operation == ingest.BUY {
+		adjustment.Amount += assignment.Fee
+	} else {
+		adjustment.Amount -= assignment.Fee
+	}
+	return adjustment
+}
+
+// Adjusts price of the underlying trades (of the same item, broker, date and currency) by premiums of assigned options
+func ApplyUnderlyingAdjustments(transactions *ingest.TransactionLog, adjustments UnderlyingAdjustments) {
+	for _, adjustment := range adjustments {
+		underlyingTrades := transactions.Purchases
+		if adjustment.Operation == ingest.SELL {
+			underlyingTrades = transactions.Sales
+		}
+		adjusted := false
+		for _, trade := range underlyingTrades {
+			if !strings.EqualFold(trade.Name, adjustment.Underlying) || !strings.EqualFold(trade.Broker, adjustment.Broker) ||
+				trade.Date.Format("2006-01-02") != adjustment.Date.Format("2006-01-02") || trade.Currency.Name != adjustment.Currency.Name {
+				continue
+			}
+			trade.BrokerAmount += adjustment.Amount
+			trade.BankAmount += adjustment.Amount
+			trade.OriginalBankAmount += adjustment.Amount
+			adjusted = true
+			log.Infof("price of '%s' at %s adjusted by %v %s of assigned option '%s'",
+				trade.Name, trade.Date.Format("02.01.2006"), adjustment.Amount, adjustment.Currency.Name, adjustment.Option.Name)
+			break
+		}
+		if !adjusted {
+			log.Warnf("underlying trade of assigned option '%s' (sheet '%s' row '%d') not found: '%s' at %s (broker '%s', currency %s) - premium %v is not applied",
+				adjustment.Option.Name, adjustment.Option.SheetName, adjustment.Option.SheetRow, adjustment.Underlying,
+				adjustment.Date.Format("02.01.2006"), adjustment.Broker, adjustment.Currency.Name, adjustment.Amount)
+		}
+	}
+}
+
+func removeClosedContracts(position []*openContract) (ret []*openContract) {
+	for _, contract := range position {
+		if contract.availableQuantity > quantityTolerance {
+			ret = append(ret, contract)
+		}
+	}
+	return
+}
+
+// value of the quantity of a trade in CZK
+func getDerivativeTradeValue(trade *ingest.TransactionLogItem, quantity float64) *AccountingValue {
+	value := quantity * trade.ItemPrice * trade.Multiplier
+	return newAccountingValue(value*trade.DayExchangeRate, value*trade.YearExchangeRate, DEFAULT_CURRENCY)
+}
+
+// part of the fee of a trade related to the quantity in CZK
+func getDerivativeTradeFee(trade *ingest.TransactionLogItem, quantity float64) *AccountingValue {
+	fee := trade.Fee * quantity / trade.Quantity
+	return newAccountingValue(fee*trade.DayExchangeRate, fee*trade.YearExchangeRate, DEFAULT_CURRENCY)
+}
+
+func getDerivativeActionName(operation ingest.TransactionType) string {
+	if operation == ingest.EXPIRY {
+		return "expiry"
+	}
+	return "assignment"
+}
@@ -0,0 +1,76 @@
+package tax
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/marty-cz/czech-tax-calculator/internal/ingest"
+)
+
+func TestCalculateDerivatives(t *testing.T) {
+	newTrade := func(operation ingest.TransactionType, contractType ingest.ContractType, name string, date time.Time, quantity, price float64) *ingest.TransactionLogItem {
+		item := createItem(operation, name, date, quantity, quantity*price*100)
+		item.ItemPrice = price
+		item.Multiplier = 100
+		item.ContractType = contractType
+		item.Underlying = "AAA"
+		return item
+	}
+	transactions := &ingest.TransactionLog{
+		Derivatives: ingest.TransactionLogItems{
+			// long call closed with gain 2 * (5 - 3) * 100 = 400
+			newTrade(ingest.BUY, ingest.CALL, "AAA C100", createDate(1, 2, 2022), 2, 3),
+			newTrade(ingest.SELL, ingest.CALL, "AAA C100", createDate(1, 3, 2022), 2, 5),
+			// short future closed in next year with loss 1 * (12 - 10) * 100 = 200
+			newTrade(ingest.SELL, ingest.FUTURE, "FUT", createDate(1, 11, 2022), 1, 10),
+			newTrade(ingest.BUY, ingest.FUTURE, "FUT", createDate(1, 2, 2023), 1, 12),
+			// short put expired with gain of premium 1 * 2 * 100 = 200
+			newTrade(ingest.SELL, ingest.PUT, "AAA P90", createDate(1, 4, 2023), 1, 2),
+			newTrade(ingest.EXPIRY, ingest.PUT, "AAA P90", createDate(1, 5, 2023), 1, 0),
+			// short put assigned - received premium 1 * 4 * 100 = 400 lowers price of the bought underlying
+			newTrade(ingest.SELL, ingest.PUT, "AAA P80", createDate(1, 4, 2023), 1, 4),
+			newTrade(ingest.ASSIGNMENT, ingest.PUT, "AAA P80", createDate(1, 6, 2023), 1, 0),
+		},
+	}
+	reports, adjustments, err := CalculateDerivatives(transactions, "2023")
+	if err != nil {
+		t.Fatalf("CalculateDerivatives() error = %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("CalculateDerivatives() reports count = %d, want 2", len(reports))
+	}
+	tests := []struct {
+		year       int
+		wantCount  int
+		wantProfit float64
+	}{
+		{2022, 1, 400},
+		{2023, 2, 0},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(tt.year), func(t *testing.T) {
+			report := reports[i]
+			if len(report.ClosedPositions) != tt.wantCount {
+				t.Errorf("ClosedPositions = %v, want count %d", report.ClosedPositions, tt.wantCount)
+			}
+			if got := report.GetIncomeCategoryBases()[DERIVATIVES].Profit.ValueWithDayExchangeRate; got != tt.wantProfit {
+				t.Errorf("GetIncomeCategoryBases() derivatives profit = %v, want %v", got, tt.wantProfit)
+			}
+		})
+	}
+
+	if len(adjustments) != 1 || adjustments[0].Operation != ingest.BUY || adjustments[0].Amount != -400 {
+		t.Fatalf("CalculateDerivatives() adjustments = %v, want single buy adjustment -400", adjustments)
+	}
+	stocks := &ingest.TransactionLog{Purchases: ingest.TransactionLogItems{createItem(ingest.BUY, "AAA", createDate(1, 6, 2023), 100, 8_000)}}
+	ApplyUnderlyingAdjustments(stocks, adjustments)
+	if got := stocks.Purchases[0].BankAmount; got != 7_600 {
+		t.Errorf("ApplyUnderlyingAdjustments() purchase price = %v, want 7600", got)
+	}
+
+	expiryWithoutPosition := &ingest.TransactionLog{Derivatives: ingest.TransactionLogItems{newTrade(ingest.EXPIRY, ingest.CALL, "AAA C100", createDate(1, 2, 2022), 1, 0)}}
+	if _, _, err := CalculateDerivatives(expiryWithoutPosition, "2022"); err == nil {
+		t.Errorf("CalculateDerivatives() expiry without open position, want error")
+	}
+}
@@ -0,0 +1,43 @@
+package tax
+
+import (
+	"math"
+)
+
+// Splits time tested revenue of the reports into exempted part (up to the yearly cap shared by all the reports)
+// and taxed part above the cap. The cap is consumed in order of the reports. The taxed part carries proportional
+// part of time tested expense and fees. Reports without a cap (0) are fully exempted.
+func ApplyTimeTestedExemptionCap(exemptionCap float64, reports ...*Report) {
+	remainingCapD, remainingCapY := exemptionCap, exemptionCap
+	for _, report := range reports {
+		if report == nil {
+			continue
+		}
+		revenue := report.TimeTestedItemRevenue
+		taxedD, taxedY := 0.0, 0.0
+		if exemptionCap > 0.0 {
+			taxedD = math.Max(0, revenue.ValueWithDayExchangeRate-remainingCapD)
+			taxedY = math.Max(0, revenue.ValueWithYearExchangeRate-remainingCapY)
+			remainingCapD = math.Max(0, remainingCapD-revenue.ValueWithDayExchangeRate)
+			remainingCapY = math.Max(0, remainingCapY-revenue.ValueWithYearExchangeRate)
+		}
+		report.TimeTestedTaxedRevenue = newAccountingValue(taxedD, taxedY, report.Currency)
+		report.TimeTestedTaxedExpense = &ValueAndFee{
+			Value: newAccountingValue(
+				getRatio(taxedD, revenue.ValueWithDayExchangeRate)*report.TimeTestedItemFifoExpense.Value.ValueWithDayExchangeRate,
+				getRatio(taxedY, revenue.ValueWithYearExchangeRate)*report.TimeTestedItemFifoExpense.Value.ValueWithYearExchangeRate,
+				report.Currency),
+			Fee: newAccountingValue(
+				getRatio(taxedD, revenue.ValueWithDayExchangeRate)*report.TimeTestedItemFifoExpense.Fee.ValueWithDayExchangeRate,
+				getRatio(taxedY, revenue.ValueWithYearExchangeRate)*report.TimeTestedItemFifoExpense.Fee.ValueWithYearExchangeRate,
+				report.Currency),
+		}
+	}
+}
+
+func getRatio(part, total float64) float64 {
+	if total == 0.0 {
+		return 0.0
+	}
+	return part / total
+}
@@ -0,0 +1,35 @@
+package tax
+
+import (
+	"testing"
+)
+
+func TestApplyTimeTestedExemptionCap(t *testing.T) {
+	newReport := func(timeTestedRevenue float64) *Report {
+		return &Report{
+			TimeTestedItemRevenue: newAccountingValue(timeTestedRevenue, timeTestedRevenue, DEFAULT_CURRENCY),
+			TimeTestedItemFifoExpense: &ValueAndFee{
+				Value: newAccountingValue(timeTestedRevenue/2, timeTestedRevenue/2, DEFAULT_CURRENCY),
+				Fee:   newAccountingValue(0, 0, DEFAULT_CURRENCY),
+			},
+			Currency: DEFAULT_CURRENCY,
+		}
+	}
+	stockReport, cryptoReport := newReport(30_000_000), newReport(20_000_000)
+	ApplyTimeTestedExemptionCap(40_000_000, stockReport, cryptoReport)
+	if got := stockReport.TimeTestedTaxedRevenue.ValueWithDayExchangeRate; got != 0 {
+		t.Errorf("ApplyTimeTestedExemptionCap() stock taxed revenue = %v, want 0", got)
+	}
+	if got := cryptoReport.TimeTestedTaxedRevenue.ValueWithDayExchangeRate; got != 10_000_000 {
+		t.Errorf("ApplyTimeTestedExemptionCap() crypto taxed revenue = %v, want 10000000", got)
+	}
+	if got := cryptoReport.TimeTestedTaxedExpense.Value.ValueWithDayExchangeRate; got != 5_000_000 {
+		t.Errorf("ApplyTimeTestedExemptionCap() crypto taxed expense = %v, want 5000000", got)
+	}
+
+	noCapReport := newReport(50_000_000)
+	ApplyTimeTestedExemptionCap(0, noCapReport)
+	if got := noCapReport.TimeTestedTaxedRevenue.ValueWithDayExchangeRate; got != 0 {
+		t.Errorf("ApplyTimeTestedExemptionCap() taxed revenue without cap = %v, want 0", got)
+	}
+}
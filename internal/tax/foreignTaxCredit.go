@@ -0,0 +1,99 @@
+package tax
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Credit method (metoda zápočtu) of tax paid abroad for a source country - rows of the attachment no. 3 (Příloha č. 3)
+type ForeignTaxCredit struct {
+	Country string
+	// ř. 321 - income from sources in the country
+	Income *AccountingValue
+	// ř. 322 - expense related to the income
+	Expense *AccountingValue
+	// ř. 324 - tax paid in the country
+	PaidTax *AccountingValue
+	// ř. 325 - coefficient (in percent) of the foreign income in the total tax base
+	CoefficientWithDayExchangeRate  float64
+	CoefficientWithYearExchangeRate float64
+	// ř. 326 - max creditable amount (proportional Czech tax)
+	MaxCreditable *AccountingValue
+	// ř. 327 - tax credited
+	Creditable *AccountingValue
+	// ř. 328 - tax not credited
+	NonCreditable *AccountingValue
+}
+
+func (x *ForeignTaxCredit) String() string {
+	return fmt.Sprintf("country:%v income:(%v) expense:(%v) paidTax:(%v) coefficient:(day:%v year:%v) maxCreditable:(%v) creditable:(%v) nonCreditable:(%v)",
+		x.Country, x.Income, x.Expense, x.PaidTax, x.CoefficientWithDayExchangeRate, x.CoefficientWithYearExchangeRate, x.MaxCreditable, x.Creditable, x.NonCreditable)
+}
+
+// difference of income and expense (ř. 323), never negative
+func (x *ForeignTaxCredit) GetPositiveDifference() *AccountingValue {
+	return newAccountingValue(
+		math.Max(0, x.Income.ValueWithDayExchangeRate-x.Expense.ValueWithDayExchangeRate),
+		math.Max(0, x.Income.ValueWithYearExchangeRate-x.Expense.ValueWithYearExchangeRate),
+		x.Income.Currency)
+}
+
+// foreign tax credits sorted by country
+type ForeignTaxCredits []*ForeignTaxCredit
+
+func (x ForeignTaxCredits) GetTotalCreditable() *AccountingValue {
+	ret := newAccountingValue(0, 0, DEFAULT_CURRENCY)
+	for _, credit := range x {
+		ret.Add(credit.Creditable)
+	}
+	return ret
+}
+
+// Calculates credit of tax paid abroad from dividends and interests per source country. The creditable amount is limited by the Czech tax
+// proportional to the part of the foreign income in the total tax base.
+func CalculateForeignTaxCredits(totalTaxBase, tax *AccountingValue, reports ...*Report) (credits ForeignTaxCredits) {
+	creditsByCountry := make(map[string]*ForeignTaxCredit)
+	for _, report := range reports {
+		if report == nil {
+			continue
+		}
+		for _, countryReports := range []map[string]*BrokerDividendReports{report.DividendReports, report.InterestReports} {
+			for country, brokerDividendReports := range countryReports {
+				credit, exist := creditsByCountry[country]
+				if !exist {
+					credit = &ForeignTaxCredit{
+						Country: country,
+						Income:  newAccountingValue(0, 0, DEFAULT_CURRENCY),
+						Expense: newAccountingValue(0, 0, DEFAULT_CURRENCY),
+						PaidTax: newAccountingValue(0, 0, DEFAULT_CURRENCY),
+					}
+					creditsByCountry[country] = credit
+					credits = append(credits, credit)
+				}
+				for _, dividendReport := range brokerDividendReports.GetAll() {
+					credit.Income.Add(dividendReport.RawRevenue.Value)
+					credit.PaidTax.Add(dividendReport.PaidTax)
+				}
+			}
+		}
+	}
+	sort.Slice(credits, func(i, j int) bool { return credits[i].Country < credits[j].Country })
+
+	for _, credit := range credits {
+		difference := credit.GetPositiveDifference()
+		credit.CoefficientWithDayExchangeRate = 100 * getRatio(difference.ValueWithDayExchangeRate, totalTaxBase.ValueWithDayExchangeRate)
+		credit.CoefficientWithYearExchangeRate = 100 * getRatio(difference.ValueWithYearExchangeRate, totalTaxBase.ValueWithYearExchangeRate)
+		credit.MaxCreditable = newAccountingValue(
+			tax.ValueWithDayExchangeRate*math.Min(100, credit.CoefficientWithDayExchangeRate)/100,
+			tax.ValueWithYearExchangeRate*math.Min(100, credit.CoefficientWithYearExchangeRate)/100,
+			DEFAULT_CURRENCY)
+		credit.Creditable = newAccountingValue(
+			math.Min(credit.PaidTax.ValueWithDayExchangeRate, credit.MaxCreditable.ValueWithDayExchangeRate),
+			math.Min(credit.PaidTax.ValueWithYearExchangeRate, credit.MaxCreditable.ValueWithYearExchangeRate),
+			DEFAULT_CURRENCY)
+		credit.NonCreditable = newAccountingValue(credit.PaidTax.ValueWithDayExchangeRate, credit.PaidTax.ValueWithYearExchangeRate, DEFAULT_CURRENCY)
+		credit.NonCreditable.Sub(credit.Creditable)
+	}
+	return
+}
@@ -0,0 +1,34 @@
+package tax
+
+import (
+	"testing"
+)
+
+func TestCalculateForeignTaxCredits(t *testing.T) {
+	newDividendReport := func(revenue, paidTax float64) *DividendReport {
+		return &DividendReport{
+			RawRevenue: &ValueAndFee{Value: newAccountingValue(revenue, revenue, DEFAULT_CURRENCY), Fee: newAccountingValue(0, 0, DEFAULT_CURRENCY)},
+			PaidTax:    newAccountingValue(paidTax, paidTax, DEFAULT_CURRENCY),
+		}
+	}
+	report := &Report{
+		DividendReports: map[string]*BrokerDividendReports{
+			"US": {"broker1": newDividendReport(10_000, 1_500), "broker2": newDividendReport(10_000, 1_500)},
+			"DE": {"broker1": newDividendReport(10_000, 2_637.5)},
+		},
+		Currency: DEFAULT_CURRENCY,
+	}
+	// total tax base 100,000 with tax 15,000
+	credits := CalculateForeignTaxCredits(newAccountingValue(100_000, 100_000, DEFAULT_CURRENCY), newAccountingValue(15_000, 15_000, DEFAULT_CURRENCY), report)
+	if len(credits) != 2 || credits[0].Country != "DE" || credits[1].Country != "US" {
+		t.Fatalf("CalculateForeignTaxCredits() = %v, want DE and US", credits)
+	}
+	// DE: coefficient 10 % => max 1,500 credited, 1,137.5 not credited
+	if got := credits[0]; got.Creditable.ValueWithDayExchangeRate != 1_500 || got.NonCreditable.ValueWithDayExchangeRate != 1_137.5 {
+		t.Errorf("CalculateForeignTaxCredits() DE = %v, want 1500 credited and 1137.5 not credited", got)
+	}
+	// US: coefficient 20 % => max 3,000, fully credited
+	if got := credits[1]; got.Creditable.ValueWithDayExchangeRate != 3_000 || got.NonCreditable.ValueWithDayExchangeRate != 0 {
+		t.Errorf("CalculateForeignTaxCredits() US = %v, want 3000 credited", got)
+	}
+}
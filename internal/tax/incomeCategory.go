@@ -0,0 +1,113 @@
+package tax
+
+import (
+	"fmt"
+	"math"
+)
+
+// category of income with its own partial tax base - losses are offset only inside of a category
+// (except of securities and derivatives which are offset with each other)
+type IncomeCategory string
+
+const (
+	// dividends and interests (§8)
+	CAPITAL_INCOME IncomeCategory = "Capital income (§8)"
+	// sold securities (§10 odst. 1 písm. b)
+	SECURITIES IncomeCategory = "Sold securities (§10)"
+	// sold other assets, e.g. crypto-currencies (§10 odst. 1 písm. b)
+	OTHER_ASSETS IncomeCategory = "Sold other assets (§10)"
+	// closed positions of derivatives - options, futures and CFDs (§10 odst. 1 písm. b)
+	DERIVATIVES IncomeCategory = "Derivatives (§10)"
+	// occasional activity - rewards and additional income (§10 odst. 1 písm. a)
+	OCCASIONAL_ACTIVITY IncomeCategory = "Occasional activity (§10)"
+)
+
+// all categories in order of the tax return
+var IncomeCategories = []IncomeCategory{CAPITAL_INCOME, SECURITIES, OTHER_ASSETS, DERIVATIVES, OCCASIONAL_ACTIVITY}
+
+// revenue and expense of an income category and its partial tax base
+type IncomeCategoryBase struct {
+	Category IncomeCategory
+	Revenue  *AccountingValue
+	// expense including fees
+	Expense *AccountingValue
+	// revenue minus expense, it may be negative
+	Profit *AccountingValue
+	// profit floored at zero (lowered by a loss of the offsetting category, see SumIncomeCategoryBases)
+	Base *AccountingValue
+}
+
+func newIncomeCategoryBase(category IncomeCategory, revenue, expense *AccountingValue) *IncomeCategoryBase {
+	profit := newAccountingValue(revenue.ValueWithDayExchangeRate, revenue.ValueWithYearExchangeRate, DEFAULT_CURRENCY)
+	profit.Sub(expense)
+	return &IncomeCategoryBase{
+		Category: category,
+		Revenue:  revenue,
+		Expense:  expense,
+		Profit:   profit,
+		Base: newAccountingValue(
+			math.Max(0, profit.ValueWithDayExchangeRate),
+			math.Max(0, profit.ValueWithYearExchangeRate),
+			DEFAULT_CURRENCY),
+	}
+}
+
+func (x *IncomeCategoryBase) String() string {
+	return fmt.Sprintf("category:%v revenue:(%v) expense:(%v) base:(%v)", x.Category, x.Revenue, x.Expense, x.Base)
+}
+
+// map of partial tax bases (value) of income categories (key)
+type IncomeCategoryBases map[IncomeCategory]*IncomeCategoryBase
+
+// Sums revenues and expenses of the same categories of all reports and floors bases at zero. A loss lowers the base
+// of its own category only, except that securities and derivatives are offset with each other (§10 odst. 4 ZDP
+// counts derivatives among securities). All categories are present.
+func SumIncomeCategoryBases(reports ...*Report) IncomeCategoryBases {
+	revenues, expenses := make(map[IncomeCategory]*AccountingValue), make(map[IncomeCategory]*AccountingValue)
+	for _, category := range IncomeCategories {
+		revenues[category] = newAccountingValue(0, 0, DEFAULT_CURRENCY)
+		expenses[category] = newAccountingValue(0, 0, DEFAULT_CURRENCY)
+	}
+	for _, report := range reports {
+		if report == nil {
+			continue
+		}
+		for category, base := range report.GetIncomeCategoryBases() {
+			revenues[category].Add(base.Revenue)
+			expenses[category].Add(base.Expense)
+		}
+	}
+	ret := make(IncomeCategoryBases)
+	for _, category := range IncomeCategories {
+		ret[category] = newIncomeCategoryBase(category, revenues[category], expenses[category])
+	}
+	offsetLosses(ret[SECURITIES], ret[DERIVATIVES])
+	return ret
+}
+
+// offsets a loss of one category with a profit of the other one (both exchange rate variants separately)
+func offsetLosses(a, b *IncomeCategoryBase) {
+	a.Base.ValueWithDayExchangeRate, b.Base.ValueWithDayExchangeRate = offsetLoss(a.Profit.ValueWithDayExchangeRate, b.Profit.ValueWithDayExchangeRate)
+	a.Base.ValueWithYearExchangeRate, b.Base.ValueWithYearExchangeRate = offsetLoss(a.Profit.ValueWithYearExchangeRate, b.Profit.ValueWithYearExchangeRate)
+}
+
+func offsetLoss(profitA, profitB float64) (baseA, baseB float64) {
+	if profitA < 0 {
+		return 0, math.Max(0, profitB+profitA)
+	}
+	if profitB < 0 {
+		return math.Max(0, profitA+profitB), 0
+	}
+	return profitA, profitB
+}
+
+// sum of partial tax bases of the categories
+func (m IncomeCategoryBases) GetTotalBase(categories ...IncomeCategory) *AccountingValue {
+	ret := newAccountingValue(0, 0, DEFAULT_CURRENCY)
+	for _, category := range categories {
+		if base, exists := m[category]; exists {
+			ret.Add(base.Base)
+		}
+	}
+	return ret
+}
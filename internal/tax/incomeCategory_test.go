@@ -0,0 +1,87 @@
+package tax
+
+import (
+	"testing"
+)
+
+func TestSumIncomeCategoryBases(t *testing.T) {
+	newReport := func(rules *YearRules, revenue, expense, additionalRevenue float64) *Report {
+		return &Report{
+			TotalItemRevenue:          newAccountingValue(revenue, revenue, DEFAULT_CURRENCY),
+			TimeTestedItemRevenue:     newAccountingValue(0, 0, DEFAULT_CURRENCY),
+			TotalItemFifoExpense:      &ValueAndFee{Value: newAccountingValue(expense, expense, DEFAULT_CURRENCY), Fee: newAccountingValue(0, 0, DEFAULT_CURRENCY)},
+			TimeTestedItemFifoExpense: newEmptyValueAndFee(DEFAULT_CURRENCY),
+			AdditionalRevenue:         &ValueAndFee{Value: newAccountingValue(additionalRevenue, additionalRevenue, DEFAULT_CURRENCY), Fee: newAccountingValue(0, 0, DEFAULT_CURRENCY)},
+			DividendReports:           map[string]*BrokerDividendReports{},
+			Rules:                     rules,
+			Currency:                  DEFAULT_CURRENCY,
+		}
+	}
+	// stock loss is not offset with crypto profit nor additional income
+	stockReport := newReport(StockRules(2023), 100_000, 150_000, 5_000)
+	cryptoReport := newReport(CryptoRules(2023), 80_000, 50_000, 0)
+	bases := SumIncomeCategoryBases(stockReport, cryptoReport)
+	tests := []struct {
+		category   IncomeCategory
+		wantProfit float64
+		wantBase   float64
+	}{
+		{CAPITAL_INCOME, 0, 0},
+		{SECURITIES, -50_000, 0},
+		{OTHER_ASSETS, 30_000, 30_000},
+		{OCCASIONAL_ACTIVITY, 5_000, 5_000},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.category), func(t *testing.T) {
+			if got := bases[tt.category].Profit.ValueWithDayExchangeRate; got != tt.wantProfit {
+				t.Errorf("SumIncomeCategoryBases() profit = %v, want %v", got, tt.wantProfit)
+			}
+			if got := bases[tt.category].Base.ValueWithDayExchangeRate; got != tt.wantBase {
+				t.Errorf("SumIncomeCategoryBases() base = %v, want %v", got, tt.wantBase)
+			}
+		})
+	}
+	if got := bases.GetTotalBase(SECURITIES, OTHER_ASSETS, OCCASIONAL_ACTIVITY).ValueWithDayExchangeRate; got != 35_000 {
+		t.Errorf("GetTotalBase() = %v, want 35000", got)
+	}
+}
+
+func TestSumIncomeCategoryBasesOffsetsDerivatives(t *testing.T) {
+	newReport := func(rules *YearRules, revenue, expense float64) *Report {
+		return &Report{
+			TotalItemRevenue:          newAccountingValue(revenue, revenue, DEFAULT_CURRENCY),
+			TimeTestedItemRevenue:     newAccountingValue(0, 0, DEFAULT_CURRENCY),
+			TotalItemFifoExpense:      &ValueAndFee{Value: newAccountingValue(expense, expense, DEFAULT_CURRENCY), Fee: newAccountingValue(0, 0, DEFAULT_CURRENCY)},
+			TimeTestedItemFifoExpense: newEmptyValueAndFee(DEFAULT_CURRENCY),
+			AdditionalRevenue:         newEmptyValueAndFee(DEFAULT_CURRENCY),
+			DividendReports:           map[string]*BrokerDividendReports{},
+			Rules:                     rules,
+			Currency:                  DEFAULT_CURRENCY,
+		}
+	}
+	tests := []struct {
+		name               string
+		stockProfit        float64
+		derivativeProfit   float64
+		wantSecuritiesBase float64
+		wantDerivativeBase float64
+	}{
+		{"Derivatives loss lowers securities gain", 100_000, -30_000, 70_000, 0},
+		{"Securities loss lowers derivatives gain", -30_000, 100_000, 0, 70_000},
+		{"Loss above gain", 20_000, -30_000, 0, 0},
+		{"Both gains", 20_000, 30_000, 20_000, 30_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bases := SumIncomeCategoryBases(
+				newReport(StockRules(2023), 200_000, 200_000-tt.stockProfit),
+				newReport(DerivativeRules(2023), 200_000, 200_000-tt.derivativeProfit))
+			if got := bases[SECURITIES].Base.ValueWithDayExchangeRate; got != tt.wantSecuritiesBase {
+				t.Errorf("SumIncomeCategoryBases() securities base = %v, want %v", got, tt.wantSecuritiesBase)
+			}
+			if got := bases[DERIVATIVES].Base.ValueWithYearExchangeRate; got != tt.wantDerivativeBase {
+				t.Errorf("SumIncomeCategoryBases() derivatives base = %v, want %v", got, tt.wantDerivativeBase)
+			}
+		})
+	}
+}
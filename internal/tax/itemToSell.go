@@ -8,13 +8,19 @@ import (
 )
 
 type ItemToSell struct {
-	buyItem           *ingest.TransactionLogItem
+	buyItem *ingest.TransactionLogItem
+	// current name of the item (it may differ from the bought one after corporate actions)
+	name string
+	// current broker holding the item (it may differ from the bought one after transfers)
+	broker            string
 	availableQuantity float64
 	soldByItems       ingest.TransactionLogItems
+	// purchase price and fee of a single item (average price in case of weighted average method)
+	unitPrice *ValueAndFee
 }
 
 func (x *ItemToSell) String() string {
-	return fmt.Sprintf("buyItem:%+v availableQuantity:%v soldByItems:%+v", x.buyItem, x.availableQuantity, &x.soldByItems)
+	return fmt.Sprintf("name:%v broker:%v buyItem:%+v availableQuantity:%v unitPrice:(%v) soldByItems:%+v", x.name, x.broker, x.buyItem, x.availableQuantity, x.unitPrice, &x.soldByItems)
 }
 
 type ItemsToSell []*ItemToSell
@@ -23,16 +29,37 @@ func convertToItemsToSell(purchases ingest.TransactionLogItems) (resItems ItemsT
 	for _, buyItem := range purchases {
 		resItems = append(resItems, &ItemToSell{
 			buyItem:           buyItem,
+			name:              buyItem.Name,
+			broker:            buyItem.Broker,
 			availableQuantity: buyItem.Quantity,
 			soldByItems:       ingest.TransactionLogItems{},
+			unitPrice:         newUnitPrice(buyItem),
 		})
 	}
 	return
 }
 
-func getAvailableItemsToSell(itemsToSell ItemsToSell, sellTransaction *ingest.TransactionLogItem) (ret ItemsToSell) {
+func newUnitPrice(buyItem *ingest.TransactionLogItem) *ValueAndFee {
+	unitPrice := newEmptyValueAndFee(DEFAULT_CURRENCY)
+	if buyItem.Quantity <= 0.0 {
+		return unitPrice
+	}
+	unitPrice.Value = newAccountingValue(
+		buyItem.BankAmount*buyItem.DayExchangeRate/buyItem.Quantity,
+		buyItem.BankAmount*buyItem.YearExchangeRate/buyItem.Quantity,
+		DEFAULT_CURRENCY)
+	unitPrice.Fee = newAccountingValue(
+		buyItem.Fee*buyItem.DayExchangeRate/buyItem.Quantity,
+		buyItem.Fee*buyItem.YearExchangeRate/buyItem.Quantity,
+		DEFAULT_CURRENCY)
+	return unitPrice
+}
+
+// items of the sold name which are not sold yet (held by the selling broker only when lots are pooled per broker)
+func getAvailableItemsToSell(itemsToSell ItemsToSell, sellTransaction *ingest.TransactionLogItem, perBrokerPools bool) (ret ItemsToSell) {
 	test := func(itemToSell *ItemToSell) bool {
-		return strings.EqualFold(itemToSell.buyItem.Name, sellTransaction.Name) && itemToSell.availableQuantity > 0.0
+		return strings.EqualFold(itemToSell.name, sellTransaction.Name) && itemToSell.availableQuantity > 0.0 &&
+			(!perBrokerPools || strings.EqualFold(itemToSell.broker, sellTransaction.Broker))
 	}
 	return filterItemsToSell(itemsToSell, test)
 }
@@ -0,0 +1,44 @@
+package tax
+
+import (
+	"fmt"
+)
+
+// Exempted revenue above the yearly limit needs to be notified to the tax office (even it is not taxed)
+type NotificationObligation struct {
+	// limit of yearly exempted revenue (in CZK)
+	Limit float64
+	// exempted time tested revenue of all the reports (time tested revenue above exemption cap is not counted)
+	ExemptRevenue                *AccountingValue
+	RequiredWithDayExchangeRate  bool
+	RequiredWithYearExchangeRate bool
+}
+
+func (x *NotificationObligation) String() string {
+	return fmt.Sprintf("limit:%v exemptRevenue:(%v) requiredWithDayExchange:%v requiredWithYearExchange:%v",
+		x.Limit, x.ExemptRevenue, x.RequiredWithDayExchangeRate, x.RequiredWithYearExchangeRate)
+}
+
+// Sums exempted time tested revenue of the reports (of the same year) and compares it with the limit.
+// Returns nil when there is no limit.
+func EvaluateNotificationObligation(limit float64, reports ...*Report) *NotificationObligation {
+	if limit <= 0.0 {
+		return nil
+	}
+	exemptRevenue := newAccountingValue(0, 0, DEFAULT_CURRENCY)
+	for _, report := range reports {
+		if report == nil {
+			continue
+		}
+		exemptRevenue.Add(report.TimeTestedItemRevenue)
+		if report.TimeTestedTaxedRevenue != nil {
+			exemptRevenue.Sub(report.TimeTestedTaxedRevenue)
+		}
+	}
+	return &NotificationObligation{
+		Limit:                        limit,
+		ExemptRevenue:                exemptRevenue,
+		RequiredWithDayExchangeRate:  exemptRevenue.ValueWithDayExchangeRate > limit,
+		RequiredWithYearExchangeRate: exemptRevenue.ValueWithYearExchangeRate > limit,
+	}
+}
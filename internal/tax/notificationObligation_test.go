@@ -0,0 +1,32 @@
+package tax
+
+import (
+	"testing"
+)
+
+func TestEvaluateNotificationObligation(t *testing.T) {
+	newReport := func(timeTestedRevenue, taxedRevenue float64) *Report {
+		return &Report{
+			TimeTestedItemRevenue:  newAccountingValue(timeTestedRevenue, timeTestedRevenue, DEFAULT_CURRENCY),
+			TimeTestedTaxedRevenue: newAccountingValue(taxedRevenue, taxedRevenue, DEFAULT_CURRENCY),
+			Currency:               DEFAULT_CURRENCY,
+		}
+	}
+	tests := []struct {
+		name    string
+		reports []*Report
+		want    bool
+	}{
+		{"Under limit", []*Report{newReport(3_000_000, 0), nil}, false},
+		{"Over limit in total", []*Report{newReport(3_000_000, 0), newReport(3_000_000, 0)}, true},
+		{"Taxed revenue is not counted", []*Report{newReport(6_000_000, 2_000_000)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EvaluateNotificationObligation(5_000_000, tt.reports...)
+			if got.RequiredWithDayExchangeRate != tt.want || got.RequiredWithYearExchangeRate != tt.want {
+				t.Errorf("EvaluateNotificationObligation() = %v, want required %v", got, tt.want)
+			}
+		})
+	}
+}
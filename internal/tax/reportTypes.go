@@ -2,22 +2,48 @@ package tax
 
 import (
 	"fmt"
+	"math"
 	"time"
 
+	"github.com/marty-cz/czech-tax-calculator/internal/ingest"
 	"github.com/marty-cz/czech-tax-calculator/internal/util"
 )
 
 type Report struct {
-	SellOperations        SellOperations
+	SellOperations SellOperations
+	// closed positions of derivatives (their revenue and expense are counted as sold items)
+	ClosedPositions       ClosedPositions
 	TimeTestedItemRevenue *AccountingValue
 	TotalItemRevenue      *AccountingValue
 	// map of dividends per broker (value) in countries (key)
-	DividendReports           map[string]*BrokerDividendReports
-	AdditionalRevenue         *ValueAndFee
+	DividendReports map[string]*BrokerDividendReports
+	// dividends of domestic (Czech) issuers per broker - settled by final withholding tax, not part of tax base
+	DomesticDividendReports BrokerDividendReports
+	// map of interests per broker (value) in countries (key)
+	InterestReports map[string]*BrokerDividendReports
+	// interests of domestic (Czech) sources per broker - settled by final withholding tax, not part of tax base
+	DomesticInterestReports BrokerDividendReports
+	// dividends and interests whose explicit PAID TAX does not match RECEIVED and AMOUNT
+	PaidTaxMismatches ingest.TransactionLogItems
+	AdditionalRevenue *ValueAndFee
+	// fair value of received rewards (staking, airdrops, mining)
+	RewardRevenue             *AccountingValue
 	TimeTestedItemFifoExpense *ValueAndFee
 	TotalItemFifoExpense      *ValueAndFee
 	Year                      time.Time
 	Currency                  *util.Currency
+	// strategy used to calculate purchase price of sold items
+	CostBasisStrategy CostBasisStrategy
+	// true when sold items are matched with buys of the same broker only
+	PerBrokerPools bool
+	// rules of taxation applied in the year
+	Rules *YearRules
+	// evaluation of yearly revenue exemption (nil when rules do not define any)
+	RevenueExemption *RevenueExemption
+	// part of time tested revenue above the yearly exemption cap (it is taxed)
+	TimeTestedTaxedRevenue *AccountingValue
+	// part of time tested expense and fees related to the taxed time tested revenue
+	TimeTestedTaxedExpense *ValueAndFee
 }
 
 func (x *Report) String() string {
@@ -29,10 +55,120 @@ func (x *Report) String() string {
 		x.AdditionalRevenue)
 }
 
+// gross revenue of dividends (§8)
+func (x *Report) GetDividendRevenue() *AccountingValue {
+	return getGrossRevenue(x.DividendReports)
+}
+
+// gross revenue of interests (§8)
+func (x *Report) GetInterestRevenue() *AccountingValue {
+	return getGrossRevenue(x.InterestReports)
+}
+
+func getGrossRevenue(reports map[string]*BrokerDividendReports) *AccountingValue {
+	ret := newAccountingValue(0, 0, DEFAULT_CURRENCY)
+	for _, brokerDividendReports := range reports {
+		for _, dividendReport := range brokerDividendReports.GetAll() {
+			ret.Add(dividendReport.RawRevenue.Value)
+		}
+	}
+	return ret
+}
+
+// Partial tax bases of income categories of the report - sold items (category by the rules, securities when unknown),
+// gross dividends and interests (capital income) and rewards with additional income (occasional activity).
+// Taxed revenue of sold items = revenue of not time tested items (nothing when exempted by yearly limit)
+// + revenue of time tested items above exemption cap.
+func (x *Report) GetIncomeCategoryBases() IncomeCategoryBases {
+	saleRevenue := newAccountingValue(0, 0, DEFAULT_CURRENCY)
+	saleRevenue.Add(x.TotalItemRevenue)
+	saleRevenue.Sub(x.TimeTestedItemRevenue)
+	saleExpense := newAccountingValue(0, 0, DEFAULT_CURRENCY)
+	saleExpense.Add(x.TotalItemFifoExpense.Value)
+	saleExpense.Add(x.TotalItemFifoExpense.Fee)
+	saleExpense.Sub(x.TimeTestedItemFifoExpense.Value)
+	saleExpense.Sub(x.TimeTestedItemFifoExpense.Fee)
+	if x.RevenueExemption != nil {
+		if x.RevenueExemption.ExemptWithDayExchangeRate {
+			saleRevenue.ValueWithDayExchangeRate, saleExpense.ValueWithDayExchangeRate = 0, 0
+		}
+		if x.RevenueExemption.ExemptWithYearExchangeRate {
+			saleRevenue.ValueWithYearExchangeRate, saleExpense.ValueWithYearExchangeRate = 0, 0
+		}
+	}
+	if x.TimeTestedTaxedRevenue != nil {
+		saleRevenue.Add(x.TimeTestedTaxedRevenue)
+		saleExpense.Add(x.TimeTestedTaxedExpense.Value)
+		saleExpense.Add(x.TimeTestedTaxedExpense.Fee)
+	}
+
+	occasionalRevenue := newAccountingValue(0, 0, DEFAULT_CURRENCY)
+	if x.RewardRevenue != nil {
+		occasionalRevenue.Add(x.RewardRevenue)
+	}
+	occasionalRevenue.Add(x.AdditionalRevenue.Value)
+
+	saleCategory := SECURITIES
+	if x.Rules != nil && x.Rules.SaleIncomeCategory != "" {
+		saleCategory = x.Rules.SaleIncomeCategory
+	}
+	capitalRevenue := x.GetDividendRevenue()
+	capitalRevenue.Add(x.GetInterestRevenue())
+	return IncomeCategoryBases{
+		CAPITAL_INCOME:      newIncomeCategoryBase(CAPITAL_INCOME, capitalRevenue, newAccountingValue(0, 0, DEFAULT_CURRENCY)),
+		saleCategory:        newIncomeCategoryBase(saleCategory, saleRevenue, saleExpense),
+		OCCASIONAL_ACTIVITY: newIncomeCategoryBase(OCCASIONAL_ACTIVITY, occasionalRevenue, x.AdditionalRevenue.Fee),
+	}
+}
+
+// Revenue of not time tested sold items is exempted when it does not exceed the yearly limit.
+// Returns nil when there is no limit.
+func (x *Report) EvaluateRevenueExemption(limit float64) *RevenueExemption {
+	if limit <= 0.0 {
+		return nil
+	}
+	revenue := newAccountingValue(x.TotalItemRevenue.ValueWithDayExchangeRate, x.TotalItemRevenue.ValueWithYearExchangeRate, x.Currency)
+	revenue.Sub(x.TimeTestedItemRevenue)
+	headroom := newAccountingValue(
+		math.Max(0, limit-revenue.ValueWithDayExchangeRate),
+		math.Max(0, limit-revenue.ValueWithYearExchangeRate),
+		x.Currency)
+	return &RevenueExemption{
+		Limit:                      limit,
+		Revenue:                    revenue,
+		Headroom:                   headroom,
+		ExemptWithDayExchangeRate:  revenue.ValueWithDayExchangeRate <= limit,
+		ExemptWithYearExchangeRate: revenue.ValueWithYearExchangeRate <= limit,
+	}
+}
+
+type RevenueExemption struct {
+	// limit of yearly revenue (in CZK)
+	Limit float64
+	// revenue counted to the limit (time tested revenue is not counted)
+	Revenue *AccountingValue
+	// remaining revenue which would be still exempted
+	Headroom                   *AccountingValue
+	ExemptWithDayExchangeRate  bool
+	ExemptWithYearExchangeRate bool
+}
+
+func (x *RevenueExemption) String() string {
+	return fmt.Sprintf("limit:%v revenue:(%v) headroom:(%v) exemptWithDayExchange:%v exemptWithYearExchange:%v",
+		x.Limit, x.Revenue, x.Headroom, x.ExemptWithDayExchangeRate, x.ExemptWithYearExchangeRate)
+}
+
+func GetExemptionVerdict(exempt bool) string {
+	if exempt {
+		return "EXEMPT"
+	}
+	return "TAXABLE"
+}
+
 // map of reports (value) in years (key)
 type Reports []*Report
 
-// map of dividends (value) in brokers (key)
+// map of dividends or interests (value) in brokers (key)
 type BrokerDividendReports map[string]*DividendReport
 
 func (m BrokerDividendReports) GetAll() map[string]*DividendReport {
@@ -49,16 +185,23 @@ func (m BrokerDividendReports) Set(broker string, report *DividendReport) error
 	return nil
 }
 
+// received dividends or interests of a broker from a country
 type DividendReport struct {
 	RawRevenue         *ValueAndFee
 	PaidTax            *AccountingValue
 	OriginalRawRevenue *ValueAndFee
 	OriginalPaidTax    *AccountingValue
-	Country    string
-	Broker     string
+	// tax withheld above the treaty rate (not creditable, may be reclaimed)
+	ExcessPaidTax         *AccountingValue
+	OriginalExcessPaidTax *AccountingValue
+	// withheld tax refunded later (already deducted from paid and excess tax)
+	RefundedTax         *AccountingValue
+	OriginalRefundedTax *AccountingValue
+	Country             string
+	Broker              string
 }
 
 func (x *DividendReport) String() string {
-	return fmt.Sprintf("country:%v broker:%v rawRevenue:(%v) paidTax:(%v)",
-		x.Country, x.Broker, x.RawRevenue, x.PaidTax)
+	return fmt.Sprintf("country:%v broker:%v rawRevenue:(%v) paidTax:(%v) excessPaidTax:(%v) refundedTax:(%v)",
+		x.Country, x.Broker, x.RawRevenue, x.PaidTax, x.ExcessPaidTax, x.RefundedTax)
 }
@@ -0,0 +1,23 @@
+package tax
+
+import (
+	"testing"
+)
+
+func TestEvaluateRevenueExemption(t *testing.T) {
+	report := &Report{
+		TotalItemRevenue:      newAccountingValue(130000, 90000, DEFAULT_CURRENCY),
+		TimeTestedItemRevenue: newAccountingValue(20000, 20000, DEFAULT_CURRENCY),
+		Currency:              DEFAULT_CURRENCY,
+	}
+	if got := report.EvaluateRevenueExemption(0); got != nil {
+		t.Errorf("EvaluateRevenueExemption() = %v, want nil for no limit", got)
+	}
+	got := report.EvaluateRevenueExemption(100000)
+	if got.ExemptWithDayExchangeRate || !got.ExemptWithYearExchangeRate {
+		t.Errorf("EvaluateRevenueExemption() = %v, want taxable with day and exempt with year exchange rate", got)
+	}
+	if got.Headroom.ValueWithDayExchangeRate != 0 || got.Headroom.ValueWithYearExchangeRate != 30000 {
+		t.Errorf("EvaluateRevenueExemption() headroom = %v, want 0 and 30000", got.Headroom)
+	}
+}
@@ -10,31 +10,66 @@ type SoldItem struct {
 	BuyItem      *ingest.TransactionLogItem
 	SoldQuantity float64
 	TimeTested   bool
+	// description of the time test rule which made the item time tested
+	TimeTestRule string
 	FifoBuy      *ValueAndFee
 	Revenue      *ValueAndFee
 }
 
 func (x *SoldItem) String() string {
-	return fmt.Sprintf("buyItem:%+v soldQuantity:%v timeTested:%v fifoBuy:(%v)",
-		x.BuyItem, x.SoldQuantity, x.TimeTested, x.FifoBuy)
+	return fmt.Sprintf("buyItem:%+v soldQuantity:%v timeTested:%v timeTestRule:%v fifoBuy:(%v)",
+		x.BuyItem, x.SoldQuantity, x.TimeTested, x.TimeTestRule, x.FifoBuy)
 }
 
 type SoldItems []*SoldItem
 
 type SellOperation struct {
-	SellItem          *ingest.TransactionLogItem
-	SoldItems         SoldItems
+	SellItem  *ingest.TransactionLogItem
+	SoldItems SoldItems
+	// quantity of the sell which is not covered by any available buy item
+	OversoldQuantity  float64
 	timeTestedRevenue *AccountingValue
 	totalRevenue      *AccountingValue
 }
 
 func (x *SellOperation) String() string {
-	return fmt.Sprintf("sellItem:%+v totalRevenue:(%v) timeTestedRevenue:(%v) soldItems:[%+v]",
-		x.SellItem, x.totalRevenue, x.timeTestedRevenue, &x.SoldItems)
+	return fmt.Sprintf("sellItem:%+v totalRevenue:(%v) timeTestedRevenue:(%v) oversoldQuantity:%v soldItems:[%+v]",
+		x.SellItem, x.totalRevenue, x.timeTestedRevenue, x.OversoldQuantity, &x.SoldItems)
+}
+
+func (x *SellOperation) GetTimeTestedRevenue() *AccountingValue {
+	return x.timeTestedRevenue
+}
+
+func (x *SellOperation) IsOversold() bool {
+	return x.OversoldQuantity > 0.0
+}
+
+func (x *SellOperation) OversoldDescription() string {
+	return fmt.Sprintf("sell of '%s' on %s (sheet '%s' row '%d') misses quantity %v in available buys",
+		x.SellItem.Name, x.SellItem.Date.Format("02.01.2006"), x.SellItem.SheetName, x.SellItem.SheetRow, x.OversoldQuantity)
 }
 
 type SellOperations []*SellOperation
 
+func (x SellOperations) GetTimeTested() (ret SellOperations) {
+	for _, sellOp := range x {
+		if sellOp.timeTestedRevenue.ValueWithDayExchangeRate > 0.0 || sellOp.timeTestedRevenue.ValueWithYearExchangeRate > 0.0 {
+			ret = append(ret, sellOp)
+		}
+	}
+	return
+}
+
+func (x SellOperations) GetOversold() (ret SellOperations) {
+	for _, sellOp := range x {
+		if sellOp.IsOversold() {
+			ret = append(ret, sellOp)
+		}
+	}
+	return
+}
+
 func convertToSellOperations(sales ingest.TransactionLogItems) (resItems SellOperations) {
 	for _, sellItem := range sales {
 		resItems = append(resItems, &SellOperation{
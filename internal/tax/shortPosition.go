@@ -0,0 +1,95 @@
+package tax
+
+import (
+	"strings"
+	"time"
+
+	"github.com/marty-cz/czech-tax-calculator/internal/ingest"
+	log "github.com/sirupsen/logrus"
+)
+
+// part of a short sale which is not covered by later buys yet
+type shortPosition struct {
+	sellItem     *ingest.TransactionLogItem
+	openQuantity float64
+}
+
+type shortPositions []*shortPosition
+
+// Splits an oversold sell to the sold part of held items (nil when nothing was held) and a short position of the rest
+func openShortPosition(sellOp *SellOperation) (*SellOperation, *shortPosition) {
+	short := &shortPosition{sellItem: sellOp.SellItem, openQuantity: sellOp.OversoldQuantity}
+	log.Infof("short position of '%s' opened at %s (quantity %v)", sellOp.SellItem.Name, sellOp.SellItem.Date.Format("02.01.2006"), sellOp.OversoldQuantity)
+	soldQuantity := sellOp.SellItem.Quantity - sellOp.OversoldQuantity
+	if soldQuantity <= quantityTolerance {
+		return nil, short
+	}
+	// revenue of sold items is already related to their quantity only
+	sellOp.SellItem = getPartOfItem(sellOp.SellItem, soldQuantity)
+	sellOp.OversoldQuantity = 0
+	return sellOp, short
+}
+
+// Covers short positions (oldest first) by items bought after the short sale till the date (inclusive) in FIFO order.
+// Returns positions which are still open and sells of the covered parts. A covered part is reported as a sell
+// in the day of its last covering buy (with exchange rates of that day), so it belongs to the year of covering.
+func coverShortPositions(shorts shortPositions, itemsToSell ItemsToSell, until time.Time, yearRules *YearRules, perBrokerPools bool) (remaining shortPositions, sellOps SellOperations) {
+	for _, short := range shorts {
+		coveringItems := filterItemsToSell(itemsToSell, func(itemToSell *ItemToSell) bool {
+			return strings.EqualFold(itemToSell.name, short.sellItem.Name) && itemToSell.availableQuantity > 0.0 &&
+				!itemToSell.buyItem.Date.Before(short.sellItem.Date) && !itemToSell.buyItem.Date.After(until) &&
+				(!perBrokerPools || strings.EqualFold(itemToSell.broker, short.sellItem.Broker))
+		})
+		coveringQuantity := 0.0
+		for _, itemToSell := range coveringItems {
+			coveringQuantity += itemToSell.availableQuantity
+		}
+		if coveredQuantity := min(coveringQuantity, short.openQuantity); coveredQuantity > quantityTolerance {
+			coverItem := getPartOfItem(short.sellItem, coveredQuantity)
+			setCoverDate(coverItem, coveringItems)
+			sellOp := convertToSellOperations(ingest.TransactionLogItems{coverItem})[0]
+			calculateSellExpense(sellOp, coveringItems, yearRules)
+			sellOps = append(sellOps, sellOp)
+			short.openQuantity -= coveredQuantity
+			log.Infof("short position of '%s' opened at %s covered till %s (quantity %v, still open %v)",
+				short.sellItem.Name, short.sellItem.Date.Format("02.01.2006"), until.Format("02.01.2006"), coveredQuantity, short.openQuantity)
+		}
+		if short.openQuantity > quantityTolerance {
+			remaining = append(remaining, short)
+		}
+	}
+	return
+}
+
+// moves a covered part of a short sale to the day of the last buy needed to cover it (items are taken in FIFO order)
+func setCoverDate(coverItem *ingest.TransactionLogItem, coveringItems ItemsToSell) {
+	quantityToCover := coverItem.Quantity
+	for _, itemToSell := range coveringItems {
+		lastBuy := itemToSell.buyItem
+		quantityToCover -= itemToSell.availableQuantity
+		if quantityToCover > quantityTolerance {
+			continue
+		}
+		if lastBuy.Currency.Name == coverItem.Currency.Name {
+			coverItem.DayExchangeRate = lastBuy.DayExchangeRate
+			coverItem.YearExchangeRate = lastBuy.YearExchangeRate
+		} else {
+			log.Warnf("short position of '%s' opened at %s is covered by a buy in other currency - exchange rates of the short sale are kept",
+				coverItem.Name, coverItem.Date.Format("02.01.2006"))
+		}
+		coverItem.Date = lastBuy.Date
+		return
+	}
+}
+
+// copy of a trade reduced to the quantity (amounts and fee are proportional)
+func getPartOfItem(item *ingest.TransactionLogItem, quantity float64) *ingest.TransactionLogItem {
+	ratio := quantity / item.Quantity
+	part := *item
+	part.Quantity = quantity
+	part.BrokerAmount *= ratio
+	part.BankAmount *= ratio
+	part.OriginalBankAmount *= ratio
+	part.Fee *= ratio
+	return &part
+}
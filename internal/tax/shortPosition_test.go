@@ -0,0 +1,86 @@
+package tax
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/marty-cz/czech-tax-calculator/internal/ingest"
+	"github.com/marty-cz/czech-tax-calculator/internal/util"
+)
+
+func TestCalculateShortSales(t *testing.T) {
+	transactions := &ingest.TransactionLog{
+		Purchases: ingest.TransactionLogItems{
+			createItem(ingest.BUY, "AAA", createDate(1, 1, 2021), 5, 5_000),
+			createItem(ingest.BUY, "AAA", createDate(1, 2, 2022), 3, 3_000),
+			createItem(ingest.BUY, "AAA", createDate(1, 3, 2022), 2, 2_400),
+		},
+		Sales: ingest.TransactionLogItems{
+			// 5 held items are sold, 3 items are sold short
+			createItem(ingest.SELL, "AAA", createDate(1, 11, 2021), 8, 9_600),
+			// the short is covered by the buy of 1.2.2022 first, so the sell takes the buy of 1.3.2022
+			createItem(ingest.SELL, "AAA", createDate(1, 4, 2022), 2, 3_000),
+		},
+	}
+	reports, err := Calculate(transactions, "2022", StockRules, FIFO, false, true, false)
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	tests := []struct {
+		year        int
+		wantSells   int
+		wantRevenue float64
+		wantExpense float64
+	}{
+		{2021, 1, 6_000, 5_000},
+		{2022, 2, 3_600 + 3_000, 3_000 + 2_400},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(tt.year), func(t *testing.T) {
+			report := reports[i]
+			if len(report.SellOperations) != tt.wantSells || len(report.SellOperations.GetOversold()) > 0 {
+				t.Errorf("Calculate() sells = %v, want %d without oversold", report.SellOperations, tt.wantSells)
+			}
+			if got := report.TotalItemRevenue.ValueWithDayExchangeRate; !util.EqWithTolerance(got, tt.wantRevenue, 0.0001) {
+				t.Errorf("Calculate() revenue = %v, want %v", got, tt.wantRevenue)
+			}
+			if got := report.TotalItemFifoExpense.Value.ValueWithDayExchangeRate; !util.EqWithTolerance(got, tt.wantExpense, 0.0001) {
+				t.Errorf("Calculate() expense = %v, want %v", got, tt.wantExpense)
+			}
+		})
+	}
+}
+
+func TestCalculateShortSaleCoveredInNextYear(t *testing.T) {
+	withRate := func(item *ingest.TransactionLogItem, rate float64) *ingest.TransactionLogItem {
+		item.DayExchangeRate, item.YearExchangeRate = rate, rate
+		return item
+	}
+	transactions := &ingest.TransactionLog{
+		Purchases: ingest.TransactionLogItems{
+			withRate(createItem(ingest.BUY, "AAA", createDate(1, 2, 2022), 2, 200), 25),
+			withRate(createItem(ingest.BUY, "AAA", createDate(1, 3, 2022), 2, 220), 26),
+		},
+		Sales: ingest.TransactionLogItems{
+			withRate(createItem(ingest.SELL, "AAA", createDate(1, 11, 2021), 3, 330), 20),
+		},
+	}
+	reports, err := Calculate(transactions, "2022", StockRules, FIFO, false, true, false)
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if len(reports) != 2 || len(reports[0].SellOperations) != 0 || len(reports[1].SellOperations) != 1 {
+		t.Fatalf("Calculate() = %v, want the short sale reported in 2022 only", reports)
+	}
+	// reported in the day of the last covering buy with its exchange rate
+	coverItem := reports[1].SellOperations[0].SellItem
+	if !coverItem.Date.Equal(createDate(1, 3, 2022)) || coverItem.DayExchangeRate != 26 {
+		t.Errorf("Calculate() covering sell = %v, want date 01.03.2022 and rate 26", coverItem)
+	}
+	if got := reports[1].TotalItemRevenue.ValueWithDayExchangeRate; !util.EqWithTolerance(got, 330*26, 0.0001) {
+		t.Errorf("Calculate() revenue = %v, want %v", got, 330*26)
+	}
+	if got := reports[1].TotalItemFifoExpense.Value.ValueWithDayExchangeRate; !util.EqWithTolerance(got, 200*25+110*26, 0.0001) {
+		t.Errorf("Calculate() expense = %v, want %v", got, 200*25+110*26)
+	}
+}
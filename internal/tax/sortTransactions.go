@@ -17,6 +17,10 @@ func sortByDate(input *ingest.TransactionLog) {
 	sort.Sort(ByDate(input.Sales))
 	sort.Sort(ByDate(input.Purchases))
 	sort.Sort(ByDate(input.Dividends))
+	sort.Sort(ByDate(input.Interests))
 	sort.Sort(ByDate(input.AdditionalIncomes))
 	sort.Sort(ByDate(input.AdditionalFees))
+	sort.Sort(ByDate(input.TaxRefunds))
+	sort.Stable(ByDate(input.CorporateActions))
+	sort.Sort(ByDate(input.Rewards))
 }
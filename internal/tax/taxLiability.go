@@ -0,0 +1,87 @@
+package tax
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/marty-cz/czech-tax-calculator/internal/rules"
+)
+
+// Personal income tax of a year computed from partial tax bases of the reports and other (user supplied) tax base
+type TaxLiability struct {
+	Year int
+	// partial tax base from capital income (§8) - gross dividends and interests
+	CapitalIncomeBase *AccountingValue
+	// partial tax base from other income (§10) - sum of bases of its categories (each is never negative)
+	OtherIncomeBase *AccountingValue
+	// partial tax bases per income category (see SumIncomeCategoryBases for offsetting of losses)
+	IncomeCategoryBases IncomeCategoryBases
+	// partial tax bases not covered by the reports (e.g. employment §6), supplied by user
+	AdditionalTaxBase float64
+	// false when the additional tax base is not supplied for the year (it is left out)
+	AdditionalTaxBaseSupplied bool
+	// sum of all partial tax bases rounded down to hundreds
+	TotalTaxBase *AccountingValue
+	// tax of total tax base (basic and higher tax rate)
+	Tax *AccountingValue
+	// tax paid abroad which is credited (limited by the Czech tax of the foreign income)
+	ForeignTaxCredit *AccountingValue
+	// credit of tax paid abroad per source country (attachment no. 3)
+	ForeignTaxCredits ForeignTaxCredits
+	// tax after foreign tax credit
+	TaxDue *AccountingValue
+	// part of the tax due caused by the reports (tax due minus tax of the additional tax base only)
+	ReportsTaxDue *AccountingValue
+}
+
+func (x *TaxLiability) String() string {
+	return fmt.Sprintf("year:%d capitalIncomeBase:(%v) otherIncomeBase:(%v) additionalTaxBase:%v totalTaxBase:(%v) tax:(%v) foreignTaxCredit:(%v) taxDue:(%v) reportsTaxDue:(%v)",
+		x.Year, x.CapitalIncomeBase, x.OtherIncomeBase, x.AdditionalTaxBase, x.TotalTaxBase, x.Tax, x.ForeignTaxCredit, x.TaxDue, x.ReportsTaxDue)
+}
+
+func CalculateTaxLiability(ruleSet *rules.RuleSet, additionalTaxBase float64, reports ...*Report) *TaxLiability {
+	incomeCategoryBases := SumIncomeCategoryBases(reports...)
+	capitalIncomeBase := incomeCategoryBases.GetTotalBase(CAPITAL_INCOME)
+	otherIncomeBase := incomeCategoryBases.GetTotalBase(SECURITIES, OTHER_ASSETS, DERIVATIVES, OCCASIONAL_ACTIVITY)
+
+	liability := TaxLiability{
+		Year:                ruleSet.Year,
+		CapitalIncomeBase:   capitalIncomeBase,
+		OtherIncomeBase:     otherIncomeBase,
+		IncomeCategoryBases: incomeCategoryBases,
+		AdditionalTaxBase:   additionalTaxBase,
+	}
+	liability.TotalTaxBase = newAccountingValue(
+		roundDownToHundreds(capitalIncomeBase.ValueWithDayExchangeRate+otherIncomeBase.ValueWithDayExchangeRate+additionalTaxBase),
+		roundDownToHundreds(capitalIncomeBase.ValueWithYearExchangeRate+otherIncomeBase.ValueWithYearExchangeRate+additionalTaxBase),
+		DEFAULT_CURRENCY)
+	liability.Tax = newAccountingValue(
+		calculateTax(ruleSet, liability.TotalTaxBase.ValueWithDayExchangeRate),
+		calculateTax(ruleSet, liability.TotalTaxBase.ValueWithYearExchangeRate),
+		DEFAULT_CURRENCY)
+	liability.ForeignTaxCredits = CalculateForeignTaxCredits(liability.TotalTaxBase, liability.Tax, reports...)
+	liability.ForeignTaxCredit = liability.ForeignTaxCredits.GetTotalCreditable()
+	liability.TaxDue = newAccountingValue(liability.Tax.ValueWithDayExchangeRate, liability.Tax.ValueWithYearExchangeRate, DEFAULT_CURRENCY)
+	liability.TaxDue.Sub(liability.ForeignTaxCredit)
+	additionalTax := calculateTax(ruleSet, roundDownToHundreds(additionalTaxBase))
+	liability.ReportsTaxDue = newAccountingValue(
+		liability.TaxDue.ValueWithDayExchangeRate-additionalTax,
+		liability.TaxDue.ValueWithYearExchangeRate-additionalTax,
+		DEFAULT_CURRENCY)
+	return &liability
+}
+
+// basic tax rate up to the threshold, higher tax rate above it
+func calculateTax(ruleSet *rules.RuleSet, taxBase float64) float64 {
+	if taxBase <= 0.0 {
+		return 0.0
+	}
+	if ruleSet.HigherTaxRate <= 0.0 || taxBase <= ruleSet.HigherTaxRateThreshold {
+		return taxBase * ruleSet.TaxRate
+	}
+	return ruleSet.HigherTaxRateThreshold*ruleSet.TaxRate + (taxBase-ruleSet.HigherTaxRateThreshold)*ruleSet.HigherTaxRate
+}
+
+func roundDownToHundreds(value float64) float64 {
+	return math.Floor(value/100) * 100
+}
@@ -0,0 +1,48 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/marty-cz/czech-tax-calculator/internal/rules"
+	"github.com/marty-cz/czech-tax-calculator/internal/util"
+)
+
+func TestCalculateTaxLiability(t *testing.T) {
+	report := &Report{
+		TotalItemRevenue:          newAccountingValue(300_000, 300_000, DEFAULT_CURRENCY),
+		TimeTestedItemRevenue:     newAccountingValue(0, 0, DEFAULT_CURRENCY),
+		TotalItemFifoExpense:      &ValueAndFee{Value: newAccountingValue(200_000, 200_000, DEFAULT_CURRENCY), Fee: newAccountingValue(0, 0, DEFAULT_CURRENCY)},
+		TimeTestedItemFifoExpense: newEmptyValueAndFee(DEFAULT_CURRENCY),
+		AdditionalRevenue:         newEmptyValueAndFee(DEFAULT_CURRENCY),
+		DividendReports: map[string]*BrokerDividendReports{
+			"US": {"broker": &DividendReport{
+				RawRevenue: &ValueAndFee{Value: newAccountingValue(10_000, 10_000, DEFAULT_CURRENCY), Fee: newAccountingValue(0, 0, DEFAULT_CURRENCY)},
+				PaidTax:    newAccountingValue(1_500, 1_500, DEFAULT_CURRENCY),
+			}},
+		},
+		Currency: DEFAULT_CURRENCY,
+	}
+	ruleSet := rules.ForYear(2023)
+	tests := []struct {
+		name              string
+		additionalTaxBase float64
+		wantTax           float64
+		wantTaxDue        float64
+	}{
+		// (100,000 + 10,000) * 0.15, credit 1,500
+		{"Basic rate only", 0, 16_500, 15_000},
+		// 1,935,552 * 0.15 + (2,110,000 - 1,935,552) * 0.23, credit 1,500
+		{"Higher rate above threshold", 2_000_000, 1_935_552*0.15 + (2_110_000-1_935_552)*0.23, 1_935_552*0.15 + (2_110_000-1_935_552)*0.23 - 1_500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculateTaxLiability(ruleSet, tt.additionalTaxBase, report, nil)
+			if !util.EqWithTolerance(got.Tax.ValueWithDayExchangeRate, tt.wantTax, 0.0001) {
+				t.Errorf("CalculateTaxLiability() tax = %v, want %v", got.Tax.ValueWithDayExchangeRate, tt.wantTax)
+			}
+			if !util.EqWithTolerance(got.TaxDue.ValueWithDayExchangeRate, tt.wantTaxDue, 0.0001) {
+				t.Errorf("CalculateTaxLiability() tax due = %v, want %v", got.TaxDue.ValueWithDayExchangeRate, tt.wantTaxDue)
+			}
+		})
+	}
+}
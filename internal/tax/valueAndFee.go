@@ -24,3 +24,15 @@ func newEmptyValueAndFee(c *util.Currency) *ValueAndFee {
 func (x *ValueAndFee) String() string {
 	return fmt.Sprintf("val:(%v) fee:(%v)", x.Value, x.Fee)
 }
+
+func (x *ValueAndFee) Add(add *ValueAndFee) {
+	x.Value.Add(add.Value)
+	x.Fee.Add(add.Fee)
+}
+
+func (x *ValueAndFee) MultiplyNew(multiplicator float64) *ValueAndFee {
+	return &ValueAndFee{
+		Value: x.Value.MultiplyNew(multiplicator),
+		Fee:   x.Fee.MultiplyNew(multiplicator),
+	}
+}
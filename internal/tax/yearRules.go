@@ -0,0 +1,83 @@
+package tax
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/marty-cz/czech-tax-calculator/internal/rules"
+	"github.com/marty-cz/czech-tax-calculator/internal/util"
+)
+
+// rules of taxation of an item type valid in a tax year
+type YearRules struct {
+	Year int
+	// all rules valid in the year
+	RuleSet *rules.RuleSet
+	// holding period (in months) after which sold items are exempted (0 = no time test)
+	TimeTestMonths int
+	// holding period (in months) for items acquired before LegacyAcquiredBefore (0 = no legacy time test)
+	LegacyTimeTestMonths int
+	LegacyAcquiredBefore time.Time
+	// yearly revenue (in CZK) of not time tested sold items, up to which the revenue is exempted (0 = no exemption)
+	RevenueExemptionLimit float64
+	// income category of sold items
+	SaleIncomeCategory IncomeCategory
+}
+
+func (x *YearRules) String() string {
+	return fmt.Sprintf("year:%d timeTestMonths:%d revenueExemptionLimit:%v ruleSet:(%v)", x.Year, x.TimeTestMonths, x.RevenueExemptionLimit, x.RuleSet)
+}
+
+func (x *YearRules) IsTimeTested(buyDate, sellDate time.Time) bool {
+	tested, _ := x.EvaluateTimeTest(buyDate, sellDate)
+	return tested
+}
+
+// Returns whether the item passed a time test and description of the applied rule (empty when not passed).
+// The time test depends on the acquisition date - items acquired before the legacy date keep the legacy (shorter) test.
+func (x *YearRules) EvaluateTimeTest(buyDate, sellDate time.Time) (bool, string) {
+	months, rule := x.TimeTestMonths, fmt.Sprintf("%d months", x.TimeTestMonths)
+	if x.LegacyTimeTestMonths > 0 && buyDate.Before(x.LegacyAcquiredBefore) {
+		months, rule = x.LegacyTimeTestMonths, fmt.Sprintf("%d months (acquired before %s)", x.LegacyTimeTestMonths, x.LegacyAcquiredBefore.Format("02.01.2006"))
+	}
+	if months > 0 && buyDate.Before(util.GetDateMonthsBefore(sellDate, months)) {
+		return true, rule
+	}
+	return false, ""
+}
+
+// provides rules of taxation of an item type for a tax year
+type RulesProvider func(year int) *YearRules
+
+func StockRules(year int) *YearRules {
+	ruleSet := rules.ForYear(year)
+	return &YearRules{
+		Year:                  year,
+		RuleSet:               ruleSet,
+		TimeTestMonths:        ruleSet.SecuritiesTimeTestMonths,
+		LegacyTimeTestMonths:  ruleSet.LegacySecuritiesTimeTestMonths,
+		LegacyAcquiredBefore:  ruleSet.LegacySecuritiesAcquiredBefore,
+		RevenueExemptionLimit: ruleSet.SecuritiesRevenueExemptionLimit,
+		SaleIncomeCategory:    SECURITIES,
+	}
+}
+
+// derivatives have neither time test nor revenue exemption
+func DerivativeRules(year int) *YearRules {
+	return &YearRules{
+		Year:               year,
+		RuleSet:            rules.ForYear(year),
+		SaleIncomeCategory: DERIVATIVES,
+	}
+}
+
+func CryptoRules(year int) *YearRules {
+	ruleSet := rules.ForYear(year)
+	return &YearRules{
+		Year:                  year,
+		RuleSet:               ruleSet,
+		TimeTestMonths:        ruleSet.CryptoTimeTestMonths,
+		RevenueExemptionLimit: ruleSet.CryptoRevenueExemptionLimit,
+		SaleIncomeCategory:    OTHER_ASSETS,
+	}
+}
@@ -0,0 +1,32 @@
+package tax
+
+import (
+	"testing"
+	"time"
+)
+
+func TestYearRulesIsTimeTested(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    *YearRules
+		buyDate  time.Time
+		sellDate time.Time
+		want     bool
+	}{
+		{"Stock 2013 - held 6 months", StockRules(2013), createDate(1, 1, 2013), createDate(2, 7, 2013), true},
+		{"Stock 2019 - held over 3 years", StockRules(2019), createDate(1, 1, 2016), createDate(2, 1, 2019), true},
+		{"Stock 2019 - held under 3 years", StockRules(2019), createDate(3, 1, 2016), createDate(2, 1, 2019), false},
+		{"Stock 2014 - acquired before 2014, held 6 months", StockRules(2014), createDate(1, 12, 2013), createDate(2, 6, 2014), true},
+		{"Stock 2014 - acquired before 2014, held under 6 months", StockRules(2014), createDate(1, 12, 2013), createDate(2, 5, 2014), false},
+		{"Stock 2014 - acquired in 2014, held 6 months", StockRules(2014), createDate(1, 1, 2014), createDate(2, 7, 2014), false},
+		{"Crypto 2024 - no time test", CryptoRules(2024), createDate(1, 1, 2016), createDate(2, 1, 2024), false},
+		{"Crypto 2025 - held over 3 years", CryptoRules(2025), createDate(1, 1, 2021), createDate(2, 1, 2025), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.IsTimeTested(tt.buyDate, tt.sellDate); got != tt.want {
+				t.Errorf("IsTimeTested() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
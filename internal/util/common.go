@@ -2,6 +2,7 @@ package util
 
 import (
 	"math"
+	"time"
 )
 
 func LeqWithTolerance(a, b, tolerance float64) bool {
@@ -14,4 +15,11 @@ func LeqWithTolerance(a, b, tolerance float64) bool {
 
 func EqWithTolerance(a, b, tolerance float64) bool {
 	return math.Abs(a - b) < tolerance
-}
\ No newline at end of file
+}
+
+// compares calendar dates only (time of day is ignored)
+func IsSameDay(a, b time.Time) bool {
+	yearA, monthA, dayA := a.Date()
+	yearB, monthB, dayB := b.Date()
+	return yearA == yearB && monthA == monthB && dayA == dayB
+}
@@ -14,6 +14,6 @@ func GetYearFromString(yearString string) (int, error) {
 	return year, nil
 }
 
-func GetDateThreeYearsBefore(date time.Time) time.Time {
-	return time.Date(date.Year()-3, date.Month(), date.Day(), date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+func GetDateMonthsBefore(date time.Time, months int) time.Time {
+	return time.Date(date.Year(), date.Month()-time.Month(months), date.Day(), date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
 }